@@ -0,0 +1,126 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxROMSize is the largest image a header can declare (size code 0x08).
+const maxROMSize = minimumROMSize << 8
+
+// romExtensions are the entry names picked from an archive by default.
+var romExtensions = []string{".gb", ".gbc"}
+
+// ReadROM reads a ROM image from a plain file, a .zip or a .gz. In a zip,
+// entry names the file to use; an empty entry picks the first .gb or .gbc.
+// It returns the image and the name of the entry it came from (the file's
+// own base name for plain files). The image is byte for byte the
+// uncompressed ROM, so New validates it as usual.
+func ReadROM(path, entry string) ([]byte, string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return readZip(path, entry)
+	case ".gz":
+		return readGzip(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	rom, err := readLimited(f)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	return rom, filepath.Base(path), nil
+}
+
+func readZip(path, entry string) ([]byte, string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if !zipEntryMatches(f, entry) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %s: %w", path, f.Name, err)
+		}
+		rom, err := readLimited(rc)
+		rc.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %s: %w", path, f.Name, err)
+		}
+		return rom, f.Name, nil
+	}
+	if entry != "" {
+		return nil, "", fmt.Errorf("%s: %w: no entry named %q", path, ErrNoROM, entry)
+	}
+	return nil, "", fmt.Errorf("%s: %w: no .gb or .gbc entry", path, ErrNoROM)
+}
+
+// zipEntryMatches reports whether f is the requested entry, or with no
+// request, a ROM file. Names are compared with and without their directory.
+func zipEntryMatches(f *zip.File, entry string) bool {
+	if f.FileInfo().IsDir() {
+		return false
+	}
+	if entry != "" {
+		return f.Name == entry || filepath.Base(f.Name) == entry
+	}
+	ext := strings.ToLower(filepath.Ext(f.Name))
+	for _, e := range romExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// readGzip decompresses a .gz. The entry name is the original file name
+// stored in the gzip header, or the archive name minus .gz.
+func readGzip(path string) ([]byte, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	defer zr.Close()
+	rom, err := readLimited(zr)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	name := zr.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return rom, name, nil
+}
+
+// readLimited reads r to the end, refusing anything larger than a cartridge
+// so a hostile archive can't exhaust memory.
+func readLimited(r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, maxROMSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > maxROMSize {
+		return nil, fmt.Errorf("%w: over %d MiB", ErrROMTooLarge, maxROMSize>>20)
+	}
+	return buf.Bytes(), nil
+}
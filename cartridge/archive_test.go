@@ -0,0 +1,113 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+func writeZip(t *testing.T, path string, files map[string][]byte, order []string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, name := range order {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(files[name])
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadEntry_Zip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "games.zip")
+	first := carttest.Builder{}.Build()
+	second := carttest.Builder{Type: 0x01, ROMSize: 0x01}.Build()
+	writeZip(t, path, map[string][]byte{
+		"readme.txt":      []byte("hello"),
+		"roms/first.gb":   first,
+		"roms/second.GBC": second,
+	}, []string{"readme.txt", "roms/first.gb", "roms/second.GBC"})
+
+	tests := []struct {
+		entry string
+		want  string
+		rom   []byte
+	}{
+		{"", "roms/first.gb", first},
+		{"second.GBC", "roms/second.GBC", second},
+		{"roms/second.GBC", "roms/second.GBC", second},
+	}
+	for _, tt := range tests {
+		cart, err := LoadEntry(path, tt.entry, Options{})
+		if err != nil {
+			t.Errorf("LoadEntry(%q) error: %v", tt.entry, err)
+			continue
+		}
+		if cart.Entry != tt.want {
+			t.Errorf("LoadEntry(%q).Entry = %q; want %q", tt.entry, cart.Entry, tt.want)
+		}
+		if rom, _, _ := ReadROM(path, tt.entry); !bytes.Equal(rom, tt.rom) {
+			t.Errorf("ReadROM(%q) image differs from the original", tt.entry)
+		}
+	}
+
+	if _, err := LoadEntry(path, "missing.gb", Options{}); !errors.Is(err, ErrNoROM) {
+		t.Errorf("missing entry error = %v; want ErrNoROM", err)
+	}
+}
+
+func TestLoadEntry_ZipWithoutROM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs.zip")
+	writeZip(t, path, map[string][]byte{"readme.txt": []byte("hi")}, []string{"readme.txt"})
+	if _, err := Load(path); !errors.Is(err, ErrNoROM) {
+		t.Errorf("Load() error = %v; want ErrNoROM", err)
+	}
+}
+
+func TestLoad_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tetris.gb.gz")
+	rom := carttest.Builder{}.Build()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(rom)
+	zw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cart, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cart.Entry != "tetris.gb" {
+		t.Errorf("Entry = %q; want tetris.gb", cart.Entry)
+	}
+	if !bytes.Equal(cart.mapper.(*romOnly).rom, rom) {
+		t.Error("decompressed image differs from the original")
+	}
+}
+
+func TestLoad_ChecksumStillVerified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.zip")
+	rom := carttest.Builder{}.Build()
+	rom[0x1000] ^= 0xFF
+	writeZip(t, path, map[string][]byte{"bad.gb": rom}, []string{"bad.gb"})
+	if _, err := Load(path); !errors.Is(err, ErrGlobalChecksum) {
+		t.Errorf("Load() error = %v; want ErrGlobalChecksum", err)
+	}
+}
@@ -0,0 +1,84 @@
+package cartridge
+
+// banks holds the ROM image and external RAM every mapper indexes into, plus
+// the bank arithmetic they all share. Bank numbers wrap around the number of
+// banks actually present, which is what the unconnected high address lines
+// do on real boards.
+type banks struct {
+	rom []byte
+	ram []byte
+}
+
+func newBanks(rom []byte, ramSize int) banks {
+	return banks{rom: rom, ram: make([]byte, ramSize)}
+}
+
+func (b *banks) ramImage() []byte {
+	return b.ram
+}
+
+// romBanks returns the number of 16KiB ROM banks.
+func (b *banks) romBanks() int {
+	return len(b.rom) / ROMBankSize
+}
+
+// ramBanks returns the number of 8KiB RAM banks (0 without RAM, 1 for the
+// 2KiB chip).
+func (b *banks) ramBanks() int {
+	return (len(b.ram) + RAMBankSize - 1) / RAMBankSize
+}
+
+// romOffset maps addr inside a 16KiB window onto ROM bank bank.
+func (b *banks) romOffset(bank int, addr uint16) int {
+	bank = wrapBank(bank, b.romBanks())
+	return bank*ROMBankSize + int(addr&(ROMBankSize-1))
+}
+
+func (b *banks) romByte(bank int, addr uint16) byte {
+	return b.rom[b.romOffset(bank, addr)]
+}
+
+// romPage returns the 256 bytes of ROM bank bank behind addr.
+func (b *banks) romPage(bank int, addr uint16) []byte {
+	offset := b.romOffset(bank, addr&0xFF00)
+	return b.rom[offset : offset+0x100]
+}
+
+// ramOffset maps addr inside 0xA000-0xBFFF onto RAM bank bank, or -1 when
+// the cartridge has no RAM. Chips smaller than a bank (2KiB) mirror.
+func (b *banks) ramOffset(bank int, addr uint16) int {
+	if len(b.ram) == 0 {
+		return -1
+	}
+	return wrapBank(bank*RAMBankSize+int(addr&(RAMBankSize-1)), len(b.ram))
+}
+
+// wrapBank reduces bank modulo n. Negative banks (from the debugger) wrap
+// from the top instead of producing a negative offset.
+func wrapBank(bank, n int) int {
+	return (bank%n + n) % n
+}
+
+func (b *banks) ramByte(bank int, addr uint16) byte {
+	offset := b.ramOffset(bank, addr)
+	if offset < 0 {
+		return 0xFF
+	}
+	return b.ram[offset]
+}
+
+func (b *banks) setRAMByte(bank int, addr uint16, data byte) {
+	if offset := b.ramOffset(bank, addr); offset >= 0 {
+		b.ram[offset] = data
+	}
+}
+
+// ramPage returns the 256 bytes of RAM bank bank behind addr, or nil when a
+// page isn't backed by a full slice (no RAM, 2KiB chip mirroring).
+func (b *banks) ramPage(bank int, addr uint16) []byte {
+	if len(b.ram) < RAMBankSize {
+		return nil
+	}
+	offset := b.ramOffset(bank, addr&0xFF00)
+	return b.ram[offset : offset+0x100]
+}
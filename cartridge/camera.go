@@ -0,0 +1,313 @@
+package cartridge
+
+import (
+	"image"
+	"image/color"
+)
+
+// Pocket Camera
+// -----------------------------
+// Registers (write-only, selected by address):
+//   0x0000-0x1FFF  RAM write enable: 0x0A (RAM is always readable)
+//   0x2000-0x3FFF  ROM bank, 6 bits, bank 0 allowed
+//   0x4000-0x5FFF  0x00-0x0F selects a RAM bank, bit 4 maps the sensor
+//                  registers at 0xA000-0xBFFF instead (mirrored every 0x80)
+//
+// Sensor registers:
+//   A000     bit 0 starts a capture and reads back 1 while busy,
+//            bits 1-2 select the sensor's filter mode (not emulated)
+//   A001     bits 0-4 gain, bits 5-6 edge mode (VH), bit 7 exclusive (N)
+//   A002-03  exposure time, big-endian, in units of 16 M-cycles
+//   A004     bits 0-2 output reference, bit 3 invert, bits 4-6 edge ratio
+//   A005     zero point and reference voltage (not emulated)
+//   A006-35  4x4 dither matrix, three thresholds per pixel
+// Only A000 can be read back, the other registers read 0x00.
+//
+// A capture takes 32446 M-cycles, plus 512 without the N bit, plus 16 per
+// exposure step. It is timed in emulated cycles (Cart.Tick), never by a
+// clock, so fast-forward and pauses behave like the real thing. The
+// 128x112 result is quantized through the dither matrix and stored as 2bpp
+// tiles (16x14 of them) at 0xA100 of RAM bank 0.
+//
+// There is no sensor model here: the image source's brightness is scaled by
+// exposure and gain, edge-enhanced and inverted as configured. That is
+// enough for the game's auto-exposure to settle on a usable picture.
+// Source: https://gbdev.io/pandocs/Gameboy_Camera.html
+// -----------------------------
+
+const (
+	CameraWidth  = 128
+	CameraHeight = 112
+
+	cameraRegisters   = 0x36
+	cameraRegMask     = 0x7F
+	cameraMapBit      = 0x10
+	cameraImageOffset = 0x0100
+
+	cameraCaptureBit   = 0x01
+	cameraExclusiveBit = 0x80
+	cameraInvertBit    = 0x08
+	cameraMatrix       = 0x06
+
+	cameraBaseCycles      = 32446
+	cameraNotNCycles      = 512
+	cameraExposureCycles  = 16
+	cameraNeutralExposure = 0x1000
+)
+
+// edgeRatios are the edge enhancement strengths selected by A004 bits 4-6.
+var edgeRatios = [8]float64{0.5, 0.75, 1, 1.25, 2, 3, 4, 5}
+
+type camera struct {
+	banks
+	ramEnabled bool
+	romBank    byte
+	ramBank    byte
+	mapped     bool // sensor registers at 0xA000
+
+	regs        [cameraRegisters]byte
+	busy        bool
+	cycles      uint64 // T-cycles since power on
+	captureDone uint64 // value of cycles when the capture ends
+	source      ImageSource
+}
+
+func newCamera(rom []byte, h *Header, source ImageSource) *camera {
+	return &camera{banks: newBanks(rom, h.RAMSize), source: source}
+}
+
+func (c *camera) tick(cycles int) {
+	c.cycles += uint64(cycles)
+}
+
+// captureCycles is how many M-cycles the sensor stays busy with the
+// current registers.
+func (c *camera) captureCycles() uint64 {
+	cycles := cameraBaseCycles + cameraExposureCycles*uint64(c.exposure())
+	if c.regs[1]&cameraExclusiveBit == 0 {
+		cycles += cameraNotNCycles
+	}
+	return cycles
+}
+
+func (c *camera) exposure() uint16 {
+	return uint16(c.regs[2])<<8 | uint16(c.regs[3])
+}
+
+// update finishes a capture whose time is up.
+func (c *camera) update() {
+	if c.busy && c.cycles >= c.captureDone {
+		c.busy = false
+		c.capture()
+	}
+}
+
+func (c *camera) readRegister(addr uint16) byte {
+	if addr&cameraRegMask != 0 {
+		return 0x00
+	}
+	c.update()
+	value := c.regs[0] &^ cameraCaptureBit
+	if c.busy {
+		value |= cameraCaptureBit
+	}
+	return value
+}
+
+func (c *camera) writeRegister(addr uint16, data byte) {
+	reg := int(addr & cameraRegMask)
+	if reg >= cameraRegisters {
+		return
+	}
+	if reg != 0 {
+		c.regs[reg] = data
+		return
+	}
+	c.update()
+	c.regs[0] = data & 0x07
+	switch {
+	case data&cameraCaptureBit != 0 && !c.busy:
+		c.busy = true
+		c.captureDone = c.cycles + 4*c.captureCycles()
+	case data&cameraCaptureBit == 0:
+		c.busy = false // writing 0 aborts the capture
+	}
+}
+
+// capture takes a frame from the source and stores the processed, dithered
+// image in RAM bank 0.
+func (c *camera) capture() {
+	pixels := c.sensorImage()
+	c.enhanceEdges(pixels)
+
+	ram := c.ram[cameraImageOffset:]
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			shade := c.dither(x, y, pixels[y][x])
+			tile := (y/8*(CameraWidth/8) + x/8) * 16
+			row := tile + y%8*2
+			bit := byte(0x80) >> (x % 8)
+			ram[row] &^= bit
+			ram[row+1] &^= bit
+			if shade&1 != 0 {
+				ram[row] |= bit
+			}
+			if shade&2 != 0 {
+				ram[row+1] |= bit
+			}
+		}
+	}
+}
+
+// sensorImage samples the source down to the sensor size and applies
+// exposure and gain. Without a source the sensor sees flat mid grey.
+func (c *camera) sensorImage() *[CameraHeight][CameraWidth]float64 {
+	var img image.Image
+	if c.source != nil {
+		img = c.source.Frame()
+	}
+	scale := float64(c.exposure()) / cameraNeutralExposure * cameraGain(c.regs[1]&0x1F)
+
+	pixels := new([CameraHeight][CameraWidth]float64)
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			pixels[y][x] = sampleGray(img, x, y) * scale
+		}
+	}
+	return pixels
+}
+
+// cameraGain maps the 5-bit gain register to a linear factor of about
+// 0.88 (gain 0) to 1.65 (gain 31).
+func cameraGain(gain byte) float64 {
+	return 0.88 + float64(gain)*0.025
+}
+
+// sampleGray returns the brightness (0-255) of the source pixel that lands
+// on sensor pixel x, y, stretching the source to fill the sensor.
+func sampleGray(img image.Image, x, y int) float64 {
+	if img == nil {
+		return 0x80
+	}
+	b := img.Bounds()
+	if b.Empty() {
+		return 0x80
+	}
+	sx := b.Min.X + x*b.Dx()/CameraWidth
+	sy := b.Min.Y + y*b.Dy()/CameraHeight
+	return float64(color.GrayModel.Convert(img.At(sx, sy)).(color.Gray).Y)
+}
+
+// enhanceEdges applies the VH edge mode: the pixel minus its neighbours
+// along the selected axes, scaled by the edge ratio. Inversion happens here
+// too since the sensor does it on the same analog output.
+func (c *camera) enhanceEdges(pixels *[CameraHeight][CameraWidth]float64) {
+	mode := c.regs[1] >> 5 & 0x03
+	ratio := edgeRatios[c.regs[4]>>4&0x07]
+	src := *pixels
+	at := func(x, y int) float64 {
+		x = min(max(x, 0), CameraWidth-1)
+		y = min(max(y, 0), CameraHeight-1)
+		return src[y][x]
+	}
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			v := src[y][x]
+			edge := 0.0
+			if mode&0x01 != 0 {
+				edge += 2*v - at(x-1, y) - at(x+1, y)
+			}
+			if mode&0x02 != 0 {
+				edge += 2*v - at(x, y-1) - at(x, y+1)
+			}
+			v += ratio * edge
+			if c.regs[4]&cameraInvertBit != 0 {
+				v = 0xFF - v
+			}
+			pixels[y][x] = v
+		}
+	}
+}
+
+// dither quantizes a brightness to a 2-bit shade (0 = white, 3 = black)
+// using the three thresholds for the pixel's position in the 4x4 matrix.
+func (c *camera) dither(x, y int, v float64) byte {
+	i := cameraMatrix + (y%4*4+x%4)*3
+	switch {
+	case v < float64(c.regs[i]):
+		return 3
+	case v < float64(c.regs[i+1]):
+		return 2
+	case v < float64(c.regs[i+2]):
+		return 1
+	}
+	return 0
+}
+
+func (c *camera) Read(addr uint16) byte {
+	if addr >= 0xA000 && addr <= 0xBFFF && c.mapped {
+		return c.readRegister(addr)
+	}
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *camera) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.ramEnabled = data&0x0F == 0x0A
+	case addr <= 0x3FFF:
+		c.romBank = data & 0x3F
+	case addr <= 0x5FFF:
+		c.mapped = data&cameraMapBit != 0
+		c.ramBank = data & 0x0F
+	case addr <= 0x7FFF:
+		// no register here
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if c.mapped {
+			c.writeRegister(addr, data)
+		} else if c.ramEnabled {
+			c.setRAMByte(int(c.ramBank), addr, data)
+		}
+	}
+}
+
+func (c *camera) Bank(addr uint16) int {
+	switch {
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return int(c.romBank)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return int(c.ramBank)
+	}
+	return 0
+}
+
+func (c *camera) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *camera) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+// ReadPage maps RAM whenever the sensor registers are not; it is readable
+// even while write-disabled.
+func (c *camera) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && !c.mapped:
+		return c.ramPage(int(c.ramBank), addr)
+	}
+	return nil
+}
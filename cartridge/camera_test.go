@@ -0,0 +1,164 @@
+package cartridge
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+func newCameraTestMMU(t *testing.T, source ImageSource) (*memory.MMU, *Cart) {
+	t.Helper()
+	cart, err := NewWithOptions(carttest.Builder{Type: 0xFC, ROMSize: 0x05, RAMSize: 0x04}.Build(), Options{Camera: source})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error: %v", err)
+	}
+	return memory.NewMMU(cart), cart
+}
+
+func flatImage(shade uint8) StaticImage {
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = shade
+	}
+	return StaticImage{Image: img}
+}
+
+// captureNow runs a capture with neutral exposure and waits for it.
+func captureNow(mmu *memory.MMU, cart *Cart) {
+	mmu.Write(0x4000, 0x10)
+	mmu.Write(0xA001, 0x80) // N set, gain 0, no edge enhancement
+	mmu.Write(0xA002, 0x10)
+	mmu.Write(0xA003, 0x00)
+	mmu.Write(0xA000, 0x01)
+	cart.Tick(CPUFrequency)
+	mmu.Read(0xA000)
+	mmu.Write(0x4000, 0x00)
+}
+
+func TestCamera_CaptureTiming(t *testing.T) {
+	mmu, cart := newCameraTestMMU(t, nil)
+	mmu.Write(0x4000, 0x10)
+	mmu.Write(0xA001, 0x80)
+	mmu.Write(0xA000, 0x01) // exposure 0: 32446 M-cycles
+
+	cart.Tick(4*32446 - 1)
+	if got := mmu.Read(0xA000); got&0x01 == 0 {
+		t.Errorf("capture finished early, A000 = 0x%X", got)
+	}
+	cart.Tick(1)
+	if got := mmu.Read(0xA000); got&0x01 != 0 {
+		t.Errorf("capture still busy, A000 = 0x%X", got)
+	}
+	if got := mmu.Read(0xA001); got != 0x00 {
+		t.Errorf("A001 read back 0x%X; want 0x00", got)
+	}
+}
+
+func TestCamera_DitherMatrix(t *testing.T) {
+	mmu, cart := newCameraTestMMU(t, flatImage(0x80))
+
+	// Column 0 of each 4x4 cell goes black, the others white.
+	mmu.Write(0x4000, 0x10)
+	for i := 0; i < 16; i++ {
+		if i%4 == 0 {
+			mmu.Write(0xA006+uint16(i*3), 0xF0)
+		}
+	}
+	captureNow(mmu, cart)
+
+	for _, addr := range []uint16{0xA100, 0xA101, 0xA10E, 0xAEFF} {
+		if got := mmu.Read(addr); got != 0x88 {
+			t.Errorf("Read(%X) = 0x%X; want 0x88", addr, got)
+		}
+	}
+	if got := mmu.Read(0xAF00); got != 0x00 {
+		t.Errorf("capture wrote past the image, Read(0xAF00) = 0x%X", got)
+	}
+}
+
+func TestCamera_Shades(t *testing.T) {
+	tests := []struct {
+		shade uint8
+		lo    byte // low bit plane of every row
+		hi    byte // high bit plane
+	}{
+		{0xFF, 0x00, 0x00}, // white
+		{0xA0, 0xFF, 0x00}, // light grey
+		{0x60, 0x00, 0xFF}, // dark grey
+		{0x00, 0xFF, 0xFF}, // black
+	}
+	for _, tt := range tests {
+		mmu, cart := newCameraTestMMU(t, flatImage(tt.shade))
+		mmu.Write(0x4000, 0x10)
+		for i := 0; i < 16; i++ {
+			mmu.Write(0xA006+uint16(i*3), 0x40)
+			mmu.Write(0xA007+uint16(i*3), 0x80)
+			mmu.Write(0xA008+uint16(i*3), 0xC0)
+		}
+		captureNow(mmu, cart)
+		if lo, hi := mmu.Read(0xA100), mmu.Read(0xA101); lo != tt.lo || hi != tt.hi {
+			t.Errorf("shade 0x%02X: tile row = %02X %02X; want %02X %02X", tt.shade, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestCamera_RAMAndROMBanking(t *testing.T) {
+	rom := carttest.Builder{Type: 0xFC, ROMSize: 0x05, RAMSize: 0x04}.Build()
+	markBanks(rom)
+	carttest.Fix(rom)
+	mmu := newTestMMU(t, rom)
+
+	mmu.Write(0x2000, 0x00)
+	if got := mmu.Read(0x4200); got != 0x00 {
+		t.Errorf("ROM bank 0 at 0x4000, Read(0x4200) = 0x%X", got)
+	}
+
+	mmu.Write(0x4000, 0x03)
+	mmu.Write(0xA000, 0x33)
+	if got := mmu.Read(0xA000); got != 0x00 {
+		t.Errorf("RAM written while write-disabled, Read(0xA000) = 0x%X", got)
+	}
+	mmu.Write(0x0000, 0x0A)
+	mmu.Write(0xA000, 0x33)
+	mmu.Write(0x0000, 0x00)
+	if got := mmu.Read(0xA000); got != 0x33 {
+		t.Errorf("RAM not readable while write-disabled, Read(0xA000) = 0x%X", got)
+	}
+}
+
+func TestFrameSequence(t *testing.T) {
+	a, b := image.NewGray(image.Rect(0, 0, 1, 1)), image.NewGray(image.Rect(0, 0, 2, 2))
+	seq := &FrameSequence{Frames: []image.Image{a, b}}
+	for i, want := range []image.Image{a, b, a} {
+		if got := seq.Frame(); got != want {
+			t.Errorf("frame %d = %v; want %v", i, got.Bounds(), want.Bounds())
+		}
+	}
+}
+
+func TestPattern(t *testing.T) {
+	p := &Pattern{Shade: Gradient}
+	first := p.Frame()
+	second := p.Frame()
+	if got := first.At(0, 0).(color.Gray).Y; got != 0 {
+		t.Errorf("first frame (0,0) = %d; want 0", got)
+	}
+	if got := second.At(CameraWidth-2, 0).(color.Gray).Y; got != 255 {
+		t.Errorf("scrolled frame (126,0) = %d; want 255", got)
+	}
+}
+
+func TestPattern_NoShadeIsGrey(t *testing.T) {
+	want, wantCart := newCameraTestMMU(t, nil)
+	captureNow(want, wantCart)
+	got, gotCart := newCameraTestMMU(t, &Pattern{})
+	captureNow(got, gotCart)
+	for addr := uint16(0xA100); addr < 0xAF00; addr++ {
+		if g, w := got.Read(addr), want.Read(addr); g != w {
+			t.Fatalf("Read(%X) = 0x%X; want 0x%X as with a nil source", addr, g, w)
+		}
+	}
+}
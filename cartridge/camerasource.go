@@ -0,0 +1,116 @@
+package cartridge
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// ImageSource feeds the Pocket Camera sensor. Frame is called once per
+// capture and may return an image of any size; it is stretched to
+// CameraWidth x CameraHeight and converted to grey. A nil source or frame
+// shows flat mid grey.
+type ImageSource interface {
+	Frame() image.Image
+}
+
+// StaticImage shows the same picture on every capture.
+type StaticImage struct {
+	Image image.Image
+}
+
+func (s StaticImage) Frame() image.Image {
+	return s.Image
+}
+
+// LoadPNG reads a PNG file into a StaticImage.
+func LoadPNG(path string) (StaticImage, error) {
+	img, err := readPNG(path)
+	if err != nil {
+		return StaticImage{}, err
+	}
+	return StaticImage{Image: img}, nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+// FrameSequence plays Frames in order, one per capture, and loops. The
+// camera captures continuously while its viewfinder is open, so this is
+// how to feed it "video".
+type FrameSequence struct {
+	Frames []image.Image
+
+	next int
+}
+
+// LoadPNGSequence reads the given PNG files, in order, into a FrameSequence.
+func LoadPNGSequence(paths ...string) (*FrameSequence, error) {
+	seq := &FrameSequence{}
+	for _, path := range paths {
+		img, err := readPNG(path)
+		if err != nil {
+			return nil, err
+		}
+		seq.Frames = append(seq.Frames, img)
+	}
+	return seq, nil
+}
+
+func (s *FrameSequence) Frame() image.Image {
+	if len(s.Frames) == 0 {
+		return nil
+	}
+	img := s.Frames[s.next%len(s.Frames)]
+	s.next = (s.next + 1) % len(s.Frames)
+	return img
+}
+
+// Pattern generates frames procedurally: Shade returns the brightness
+// (0 = black, 255 = white) of sensor pixel x, y in the frame-th capture.
+// Without a Shade the sensor sees flat mid grey, like a nil source.
+type Pattern struct {
+	Shade func(x, y, frame int) uint8
+
+	frame int
+}
+
+func (p *Pattern) Frame() image.Image {
+	if p.Shade == nil {
+		return nil
+	}
+	img := image.NewGray(image.Rect(0, 0, CameraWidth, CameraHeight))
+	for y := 0; y < CameraHeight; y++ {
+		for x := 0; x < CameraWidth; x++ {
+			img.SetGray(x, y, color.Gray{Y: p.Shade(x, y, p.frame)})
+		}
+	}
+	p.frame++
+	return img
+}
+
+// Gradient is a Pattern shade: a horizontal ramp from black to white that
+// scrolls one pixel per capture.
+func Gradient(x, y, frame int) uint8 {
+	return uint8((x + frame) % CameraWidth * 255 / (CameraWidth - 1))
+}
+
+// Checkerboard is a Pattern shade: 16-pixel black and white squares.
+func Checkerboard(x, y, frame int) uint8 {
+	if (x/16+y/16)%2 == 0 {
+		return 0xFF
+	}
+	return 0x00
+}
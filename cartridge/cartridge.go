@@ -0,0 +1,234 @@
+package cartridge
+
+import (
+	"fmt"
+
+	"github.com/leaf/gameboy/memory"
+	"github.com/leaf/gameboy/patch"
+)
+
+// mapper is what every memory bank controller implements: the bus interface
+// plus the debugger and page table extensions of the MMU.
+type mapper interface {
+	memory.BankedCartridge
+	memory.PagedCartridge
+
+	// ramImage returns the cartridge's persistent memory (RAM or EEPROM).
+	ramImage() []byte
+}
+
+// tickMapper is implemented by mappers with hardware that runs on the
+// system clock rather than on bus accesses.
+type tickMapper interface {
+	tick(cycles int)
+}
+
+// Cart is a loaded cartridge: the parsed header plus the memory bank
+// controller chosen from it. It plugs straight into memory.NewMMU.
+type Cart struct {
+	Header *Header
+	// Entry is the file the ROM was loaded from: the archive entry for a
+	// zip or gzip, the base name for a plain file. Empty for New.
+	Entry string
+
+	mapper    mapper
+	freezeRTC bool
+}
+
+// Options relaxes validation and configures cartridge hardware.
+type Options struct {
+	// IgnoreGlobalChecksum accepts ROMs whose global checksum is wrong.
+	// Real hardware never checks it, and homebrew often gets it wrong.
+	IgnoreGlobalChecksum bool
+
+	// Clock drives cartridge real-time clocks. Nil means HostClock.
+	Clock Clock
+
+	// OnRumble is called whenever a rumble cartridge turns its motor on or
+	// off. It runs on the emulation goroutine, keep it short.
+	OnRumble func(on bool)
+
+	// Tilt feeds the MBC7 accelerometer. Nil means the cart is held level.
+	Tilt TiltSource
+
+	// Infrared connects the HuC1/HuC3 IR port. Nil means no light is ever
+	// seen and the LED goes nowhere.
+	Infrared InfraredPort
+
+	// OnTone is called when a HuC3 cart asks its speaker to play a tone.
+	OnTone func(tone byte)
+
+	// FreezeRTC makes a clock restored from a save resume where it left
+	// off instead of catching up with the time the emulator was closed.
+	FreezeRTC bool
+
+	// Patches are IPS, UPS or BPS files that LoadEntry applies in order, in
+	// memory, before validation. The ROM file is never touched. Patched
+	// images skip the global checksum, which patches rarely update.
+	Patches []string
+
+	// Camera is what the Pocket Camera sensor sees. Nil shows flat grey.
+	Camera ImageSource
+}
+
+// New validates rom and builds the matching memory bank controller.
+// The cartridge takes ownership of rom, debugger pokes write into it.
+func New(rom []byte) (*Cart, error) {
+	return NewWithOptions(rom, Options{})
+}
+
+// NewWithOptions is New with explicit options.
+//
+// MMM01 multicarts are recognised by the menu header at the end of the ROM;
+// their global checksum is not verified since no single header covers the
+// whole image.
+func NewWithOptions(rom []byte, opts Options) (*Cart, error) {
+	if h := mmm01Header(rom); h != nil {
+		return &Cart{Header: h, mapper: newMMM01(rom, h)}, nil
+	}
+	h, err := ParseHeader(rom)
+	if err != nil {
+		return nil, err
+	}
+	rom = rom[:h.ROMSize] // drop an overdump
+	if !opts.IgnoreGlobalChecksum {
+		if err := VerifyGlobalChecksum(rom); err != nil {
+			return nil, err
+		}
+	}
+	m, err := newMapper(rom, h, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Cart{Header: h, mapper: m, freezeRTC: opts.FreezeRTC}, nil
+}
+
+// Load reads a ROM file, which may be zipped or gzipped, and calls New.
+func Load(path string) (*Cart, error) {
+	return LoadEntry(path, "", Options{})
+}
+
+// LoadEntry is Load with explicit options and, for zip archives, the entry
+// to load (empty picks the first .gb or .gbc). Cart.Entry reports which
+// entry was used.
+func LoadEntry(path, entry string, opts Options) (*Cart, error) {
+	rom, name, err := ReadROM(path, entry)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Patches) > 0 {
+		if rom, err = patch.ApplyFiles(rom, opts.Patches...); err != nil {
+			return nil, err
+		}
+		opts.IgnoreGlobalChecksum = true
+	}
+	cart, err := NewWithOptions(rom, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+	}
+	cart.Entry = name
+	return cart, nil
+}
+
+// newMapper picks the memory bank controller named by the header.
+func newMapper(rom []byte, h *Header, opts Options) (mapper, error) {
+	switch h.Type.MBC {
+	case ROMOnly:
+		return newROMOnly(rom, h), nil
+	case MBC1:
+		return newMBC1(rom, h), nil
+	case MBC2:
+		return newMBC2(rom, h), nil
+	case MBC3:
+		return newMBC3(rom, h, opts.Clock), nil
+	case MBC5:
+		return newMBC5(rom, h, opts.OnRumble), nil
+	case MBC7:
+		return newMBC7(rom, h, opts.Tilt), nil
+	case MMM01:
+		return newMMM01(rom, h), nil
+	case PocketCamera:
+		return newCamera(rom, h, opts.Camera), nil
+	case HuC1:
+		return newHuC1(rom, h, opts.Infrared), nil
+	case HuC3:
+		return newHuC3(rom, h, opts.Clock, opts.Infrared, opts.OnTone), nil
+	}
+	return nil, fmt.Errorf("%w: %s (type 0x%02X)", ErrUnsupportedMBC, h.Type.MBC, h.Type.Code)
+}
+
+// RAM returns the cartridge's external RAM (or EEPROM) as one flat image,
+// bank 0 first. Writes to the slice go straight to the cartridge.
+func (c *Cart) RAM() []byte {
+	return c.mapper.ramImage()
+}
+
+// LoadRAM replaces the external RAM contents, e.g. from a save file.
+func (c *Cart) LoadRAM(data []byte) error {
+	ram := c.mapper.ramImage()
+	if len(data) != len(ram) {
+		return fmt.Errorf("%w: cartridge has %d bytes of RAM, got %d", ErrInconsistent, len(ram), len(data))
+	}
+	copy(ram, data)
+	return nil
+}
+
+// Tick advances the cartridge's own hardware (the Pocket Camera sensor) by
+// cycles T-cycles. Call it with the cycles of every CPU step; in CGB double
+// speed mode, pass half of them. Real-time clocks follow Options.Clock
+// instead.
+func (c *Cart) Tick(cycles int) {
+	if m, ok := c.mapper.(tickMapper); ok {
+		m.tick(cycles)
+	}
+}
+
+// RAMSize returns the bytes of RAM the game reaches at 0xA000-0xBFFF. MBC7
+// keeps its EEPROM behind a serial port there, so it has none.
+func (c *Cart) RAMSize() int {
+	if c.Header.Type.MBC == MBC7 {
+		return 0
+	}
+	return len(c.mapper.ramImage())
+}
+
+// PeekRAM copies RAM bank bank into dst, see memory.RAMBankedCartridge.
+func (c *Cart) PeekRAM(bank int, dst []byte) int {
+	size := c.RAMSize()
+	if size == 0 {
+		return 0
+	}
+	banks := (size + RAMBankSize - 1) / RAMBankSize
+	offset := wrapBank(bank, banks) * RAMBankSize
+	n := copy(dst[:min(len(dst), size, RAMBankSize)], c.mapper.ramImage()[offset:])
+	if c.Header.Type.MBC == MBC2 {
+		for i := range dst[:n] {
+			dst[i] |= 0xF0 // only the low nibble exists
+		}
+	}
+	return n
+}
+
+func (c *Cart) Read(addr uint16) byte {
+	return c.mapper.Read(addr)
+}
+
+func (c *Cart) Write(addr uint16, data byte) {
+	c.mapper.Write(addr, data)
+}
+
+func (c *Cart) Bank(addr uint16) int {
+	return c.mapper.Bank(addr)
+}
+
+func (c *Cart) PeekBank(bank int, addr uint16) byte {
+	return c.mapper.PeekBank(bank, addr)
+}
+
+func (c *Cart) PokeBank(bank int, addr uint16, data byte) {
+	c.mapper.PokeBank(bank, addr, data)
+}
+
+func (c *Cart) ReadPage(addr uint16) []byte {
+	return c.mapper.ReadPage(addr)
+}
@@ -0,0 +1,192 @@
+package cartridge
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+func TestNew_ROMOnly(t *testing.T) {
+	rom := carttest.Builder{Type: 0x08, RAMSize: 0x02}.Build()
+	rom[0x0150] = 0x11
+	rom[0x4000] = 0x22
+	carttest.Fix(rom)
+
+	cart, err := New(rom)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	mmu := memory.NewMMU(cart)
+
+	if got := mmu.Read(0x0150); got != 0x11 {
+		t.Errorf("Read(0x0150) = 0x%X; want 0x11", got)
+	}
+	if got := mmu.Read(0x4000); got != 0x22 {
+		t.Errorf("Read(0x4000) = 0x%X; want 0x22", got)
+	}
+
+	mmu.Write(0x0150, 0x99)
+	if got := mmu.Read(0x0150); got != 0x11 {
+		t.Errorf("ROM was writable, Read(0x0150) = 0x%X", got)
+	}
+	mmu.Write(0xA123, 0x33)
+	if got := mmu.Read(0xA123); got != 0x33 {
+		t.Errorf("Read(0xA123) = 0x%X; want 0x33 from cart RAM", got)
+	}
+}
+
+func TestNew_GlobalChecksum(t *testing.T) {
+	rom := carttest.Builder{}.Build()
+	rom[0x1000] = 0xAB
+
+	if _, err := New(rom); !errors.Is(err, ErrGlobalChecksum) {
+		t.Errorf("New() error = %v; want ErrGlobalChecksum", err)
+	}
+	if _, err := NewWithOptions(rom, Options{IgnoreGlobalChecksum: true}); err != nil {
+		t.Errorf("NewWithOptions(IgnoreGlobalChecksum) error = %v", err)
+	}
+}
+
+func TestNew_Overdump(t *testing.T) {
+	rom := carttest.Builder{Type: 0x01, ROMSize: 0x01}.Build() // 4 banks
+	markBanks(rom)
+	extra := bytes.Repeat([]byte{0x55}, 4*ROMBankSize)
+	cart, err := New(append(rom, extra...))
+	if err != nil {
+		t.Fatalf("New() error on an overdump: %v", err)
+	}
+	mmu := memory.NewMMU(cart)
+	mmu.Write(0x2000, 0x05)
+	if got := mmu.Read(0x4200); got != 0x01 {
+		t.Errorf("bank 5 read 0x%X; want bank 1, the overdump is dropped", got)
+	}
+
+	if _, err := New(rom[:len(rom)-ROMBankSize]); !errors.Is(err, ErrTruncated) {
+		t.Errorf("New() on a short image error = %v; want ErrTruncated", err)
+	}
+}
+
+func TestNew_UnsupportedMBC(t *testing.T) {
+	rom := carttest.Builder{Type: 0xFD}.Build() // TAMA5
+	if _, err := New(rom); !errors.Is(err, ErrUnsupportedMBC) {
+		t.Errorf("New() error = %v; want ErrUnsupportedMBC", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.gb")
+	if err := os.WriteFile(path, carttest.Builder{Title: "TEST"}.Build(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cart, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cart.Header.Title != "TEST" {
+		t.Errorf("Title = %q; want TEST", cart.Header.Title)
+	}
+}
+
+func TestLoadEntry_Patches(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.gb")
+	rom := carttest.Builder{}.Build()
+	if err := os.WriteFile(path, rom, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ips := filepath.Join(dir, "hack.ips")
+	if err := os.WriteFile(ips, []byte("PATCH\x00\x10\x00\x00\x01\x42EOF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cart, err := LoadEntry(path, "", Options{Patches: []string{ips}})
+	if err != nil {
+		t.Fatalf("LoadEntry() error: %v", err)
+	}
+	if got := cart.Read(0x1000); got != 0x42 {
+		t.Errorf("Read(0x1000) = 0x%X; want the patched 0x42", got)
+	}
+	if disk, _ := os.ReadFile(path); disk[0x1000] != rom[0x1000] {
+		t.Error("patching modified the ROM file")
+	}
+}
+
+func TestPeekBank_NegativeBank(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  byte
+	}{
+		{"MBC1", 0x03},
+		{"MBC3", 0x13},
+		{"MBC5", 0x1B},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rom := carttest.Builder{Type: tt.typ, ROMSize: 0x02, RAMSize: 0x03}.Build() // 8 ROM, 4 RAM banks
+			markBanks(rom)
+			cart, err := New(rom)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			if got := cart.PeekBank(-1, 0x4200); got != 7 {
+				t.Errorf("PeekBank(-1, 0x4200) = %d; want bank 7", got)
+			}
+			cart.PokeBank(3, 0xA010, 0x5A)
+			if got := cart.PeekBank(-1, 0xA010); got != 0x5A {
+				t.Errorf("PeekBank(-1, 0xA010) = 0x%X; want 0x5A from RAM bank 3", got)
+			}
+			cart.PokeBank(-2, 0xA010, 0xA5)
+			if got := cart.PeekBank(2, 0xA010); got != 0xA5 {
+				t.Errorf("PokeBank(-2) landed elsewhere, PeekBank(2, 0xA010) = 0x%X", got)
+			}
+		})
+	}
+}
+
+func TestPages_FollowRegisterWrites(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  byte
+	}{
+		{"MBC1", 0x03},
+		{"MBC3", 0x13},
+		{"MBC5", 0x1B},
+		{"Camera", 0xFC},
+		{"HuC1", 0xFF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rom := carttest.Builder{Type: tt.typ, ROMSize: 0x04, RAMSize: 0x03}.Build() // 32 ROM, 4 RAM banks
+			markBanks(rom)
+			cart, err := New(rom)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			for bank := 0; bank < 4; bank++ {
+				cart.PokeBank(bank, 0xA000, byte(0x80|bank))
+			}
+			mmu := memory.NewMMU(cart)
+
+			rng := rand.New(rand.NewSource(1))
+			registers := []uint16{0x0000, 0x2000, 0x3000, 0x4000, 0x6000}
+			for i := 0; i < 2000; i++ {
+				data := byte(rng.Intn(256))
+				if rng.Intn(4) == 0 {
+					data = 0x0A // RAM enable
+				}
+				mmu.Write(registers[rng.Intn(len(registers))], data)
+				for _, addr := range []uint16{0x0200, 0x4200, 0xA000} {
+					if got, want := mmu.Read(addr), cart.Read(addr); got != want {
+						t.Fatalf("write %d: Read(0x%04X) = 0x%X through the page table; cartridge has 0x%X", i, addr, got, want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Package carttest builds Game Boy ROM images for tests: pick a title,
+// cartridge type and sizes, place code at addresses or in banks, and Build
+// fills in the logo, header checksum and global checksum the way rgbfix -v
+// does. Cartridge serves an image to memory.MMU without a real mapper.
+//
+// carttest imports neither memory nor cartridge, so tests of both packages
+// (and everything above them) can use it.
+package carttest
+
+import "fmt"
+
+// Header layout, mirrored from the cartridge package.
+const (
+	logoAddr           = 0x0104
+	titleAddr          = 0x0134
+	titleSize          = 16
+	cgbFlagAddr        = 0x0143
+	sgbFlagAddr        = 0x0146
+	typeAddr           = 0x0147
+	romSizeAddr        = 0x0148
+	ramSizeAddr        = 0x0149
+	versionAddr        = 0x014C
+	headerChecksumAddr = 0x014D
+	globalChecksumAddr = 0x014E
+
+	// BankSize is the size of a 16KiB ROM bank.
+	BankSize = 0x4000
+	// RAMBankSize is the size of an 8KiB external RAM bank.
+	RAMBankSize    = 0x2000
+	minimumROMSize = 2 * BankSize
+	maxROMSizeCode = 0x08
+)
+
+// Logo is the Nintendo logo the boot ROM checks; a copy of cartridge.Logo.
+var Logo = [48]byte{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83, 0x00, 0x0C, 0x00, 0x0D,
+	0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E, 0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99,
+	0xBB, 0xBB, 0x67, 0x63, 0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+// Builder describes a ROM image. The zero value builds a valid 32KiB
+// ROM-only image full of zeros (NOPs).
+type Builder struct {
+	Title   string
+	Type    byte // 0x0147, e.g. 0x00 ROM only, 0x01 MBC1, 0x1B MBC5+RAM+BATTERY
+	ROMSize byte // 0x0148 size code, grown automatically to fit placed code
+	RAMSize byte // 0x0149 size code
+	CGBFlag byte // 0x0143, 0x80 CGB enhanced, 0xC0 CGB only
+	SGBFlag byte // 0x0146, 0x03 SGB functions
+	Version byte // 0x014C
+	Fill    byte // value of every byte nothing was placed at
+
+	chunks []chunk
+}
+
+type chunk struct {
+	offset int
+	data   []byte
+}
+
+// At places data at a CPU address in the default mapping: 0x0000-0x3FFF
+// is bank 0, 0x4000-0x7FFF bank 1.
+func (b *Builder) At(addr uint16, data ...byte) *Builder {
+	if addr > 0x7FFF {
+		panic(fmt.Sprintf("carttest: At(0x%04X) is outside ROM", addr))
+	}
+	return b.place(int(addr), data)
+}
+
+// AtBank places data in ROM bank bank at addr, which may be given either
+// as 0x0000-0x3FFF or in the switchable window 0x4000-0x7FFF.
+func (b *Builder) AtBank(bank int, addr uint16, data ...byte) *Builder {
+	return b.place(bank*BankSize+int(addr%BankSize), data)
+}
+
+func (b *Builder) place(offset int, data []byte) *Builder {
+	b.chunks = append(b.chunks, chunk{offset: offset, data: append([]byte(nil), data...)})
+	return b
+}
+
+// Build returns the image: padded to a power of two that fits everything
+// placed (and at least the declared ROM size), with the title, type and
+// sizes written and the logo and both checksums fixed. Code placed over
+// the header is overwritten by it.
+func (b Builder) Build() []byte {
+	sizeCode := b.ROMSize
+	end := 0
+	for _, c := range b.chunks {
+		end = max(end, c.offset+len(c.data))
+	}
+	for minimumROMSize<<sizeCode < end && sizeCode < maxROMSizeCode {
+		sizeCode++
+	}
+	if end > minimumROMSize<<sizeCode {
+		panic(fmt.Sprintf("carttest: code ends at 0x%X, past the largest cartridge", end))
+	}
+
+	rom := make([]byte, minimumROMSize<<sizeCode)
+	if b.Fill != 0 {
+		for i := range rom {
+			rom[i] = b.Fill
+		}
+	}
+	for _, c := range b.chunks {
+		copy(rom[c.offset:], c.data)
+	}
+
+	title := make([]byte, titleSize)
+	copy(title, b.Title)
+	if b.CGBFlag&0x80 != 0 {
+		title = title[:cgbFlagAddr-titleAddr]
+	}
+	copy(rom[titleAddr:], title)
+	if b.CGBFlag&0x80 != 0 {
+		rom[cgbFlagAddr] = b.CGBFlag
+	}
+	rom[sgbFlagAddr] = b.SGBFlag
+	rom[typeAddr] = b.Type
+	rom[romSizeAddr] = sizeCode
+	rom[ramSizeAddr] = b.RAMSize
+	rom[versionAddr] = b.Version
+	Fix(rom)
+	return rom
+}
+
+// Cartridge builds the image and wraps it in a Cartridge with the RAM
+// banks RAMSize declares, at least one.
+func (b Builder) Cartridge() *Cartridge {
+	c := NewCartridge(b.Build())
+	if banks := ramBanks[b.RAMSize]; banks > 1 {
+		c.RAM = make([]byte, banks*RAMBankSize)
+	}
+	return c
+}
+
+// ramBanks is the number of 8KiB banks for each RAM size code.
+var ramBanks = map[byte]int{0x02: 1, 0x03: 4, 0x04: 16, 0x05: 8}
+
+// Fix writes the logo and both checksums into rom, like rgbfix -v.
+func Fix(rom []byte) {
+	copy(rom[logoAddr:], Logo[:])
+
+	var header byte
+	for addr := titleAddr; addr < headerChecksumAddr; addr++ {
+		header = header - rom[addr] - 1
+	}
+	rom[headerChecksumAddr] = header
+
+	var global uint16
+	for i, v := range rom {
+		if i != globalChecksumAddr && i != globalChecksumAddr+1 {
+			global += uint16(v)
+		}
+	}
+	rom[globalChecksumAddr] = byte(global >> 8)
+	rom[globalChecksumAddr+1] = byte(global)
+}
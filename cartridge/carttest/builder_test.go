@@ -0,0 +1,74 @@
+package carttest_test
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge"
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+func TestLogoMatchesCartridge(t *testing.T) {
+	if carttest.Logo != cartridge.Logo {
+		t.Error("carttest.Logo differs from cartridge.Logo")
+	}
+}
+
+func TestBuild_PassesValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		b    carttest.Builder
+	}{
+		{"zero value", carttest.Builder{}},
+		{"MBC5 with RAM", carttest.Builder{Title: "POKEMON", Type: 0x1B, ROMSize: 0x02, RAMSize: 0x03}},
+		{"CGB only", carttest.Builder{Title: "COLOR", CGBFlag: 0xC0, Type: 0x19}},
+		{"filled", carttest.Builder{Fill: 0xFF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart, err := cartridge.New(tt.b.Build())
+			if err != nil {
+				t.Fatalf("cartridge.New() error: %v", err)
+			}
+			if cart.Header.Title != tt.b.Title {
+				t.Errorf("Title = %q; want %q", cart.Header.Title, tt.b.Title)
+			}
+		})
+	}
+}
+
+func TestBuild_GrowsToFitCode(t *testing.T) {
+	b := &carttest.Builder{Type: 0x19}
+	b.AtBank(9, 0x4000, 0x12, 0x34)
+	rom := b.Build()
+	if len(rom) != 16*carttest.BankSize {
+		t.Fatalf("len = %d; want 16 banks", len(rom))
+	}
+
+	cart, err := cartridge.New(rom)
+	if err != nil {
+		t.Fatalf("cartridge.New() error: %v", err)
+	}
+	mmu := memory.NewMMU(cart)
+	if got := mmu.PeekBank(9, 0x4001); got != 0x34 {
+		t.Errorf("PeekBank(9, 0x4001) = 0x%X; want 0x34", got)
+	}
+}
+
+func TestCartridge(t *testing.T) {
+	b := &carttest.Builder{}
+	b.At(0x0150, 0xC3, 0x50, 0x01).AtBank(2, 0x0000, 0xAB)
+	mmu := memory.NewMMU(b.Cartridge())
+
+	if got := mmu.Read(0x0150); got != 0xC3 {
+		t.Errorf("Read(0x0150) = 0x%X; want 0xC3", got)
+	}
+	mmu.Write(0x2000, 0x02)
+	if got := mmu.Read(0x4000); got != 0xAB {
+		t.Errorf("bank 2 at 0x4000: Read(0x4000) = 0x%X; want 0xAB", got)
+	}
+	mmu.Write(0xA010, 0x5A)
+	if got := mmu.Read(0xA010); got != 0x5A {
+		t.Errorf("RAM Read(0xA010) = 0x%X; want 0x5A", got)
+	}
+}
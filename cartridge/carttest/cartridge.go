@@ -0,0 +1,116 @@
+package carttest
+
+// Cartridge is a minimal cartridge for tests that don't care about a real
+// memory bank controller:
+//
+//	0x0000-0x3FFF  ROM bank 0
+//	0x2000-0x3FFF  writes select the bank at 0x4000 (any value, 0 included)
+//	0x4000-0x5FFF  writes select the RAM bank at 0xA000
+//	0x4000-0x7FFF  selected ROM bank, 1 at power on
+//	0xA000-0xBFFF  selected 8KiB RAM bank, always enabled
+//
+// It implements memory.Cartridge plus the debugger and page table
+// extensions (memory.BankedCartridge, memory.PagedCartridge,
+// memory.RAMBankedCartridge).
+type Cartridge struct {
+	ROM     []byte
+	RAM     []byte // whole 8KiB banks, bank 0 first
+	bank    int
+	ramBank int
+
+	// Writes counts writes to 0x0000-0x7FFF, i.e. mapper register writes.
+	Writes int
+}
+
+// NewCartridge serves rom, which must be a whole number of 16KiB banks,
+// with one 8KiB RAM bank.
+func NewCartridge(rom []byte) *Cartridge {
+	return &Cartridge{ROM: rom, RAM: make([]byte, RAMBankSize), bank: 1}
+}
+
+func (c *Cartridge) Read(addr uint16) byte {
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *Cartridge) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.Writes++
+		switch {
+		case addr >= 0x2000 && addr <= 0x3FFF:
+			c.bank = int(data)
+		case addr >= 0x4000 && addr <= 0x5FFF:
+			c.ramBank = int(data)
+		}
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.RAM[c.ramOffset(c.ramBank, addr)] = data
+	}
+}
+
+// Bank returns the ROM bank at addr, the RAM bank at 0xA000-0xBFFF, or 0.
+func (c *Cartridge) Bank(addr uint16) int {
+	switch {
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return c.bank
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramBank
+	}
+	return 0
+}
+
+// RAMSize returns len(RAM).
+func (c *Cartridge) RAMSize() int {
+	return len(c.RAM)
+}
+
+// PeekRAM copies RAM bank bank into dst.
+func (c *Cartridge) PeekRAM(bank int, dst []byte) int {
+	offset := c.ramOffset(bank, 0xA000)
+	return copy(dst[:min(len(dst), RAMBankSize)], c.RAM[offset:])
+}
+
+func (c *Cartridge) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.ROM[c.offset(bank, addr)]
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.RAM[c.ramOffset(bank, addr)]
+	}
+	return 0xFF
+}
+
+func (c *Cartridge) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.ROM[c.offset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.RAM[c.ramOffset(bank, addr)] = data
+	}
+}
+
+func (c *Cartridge) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		offset := c.offset(c.Bank(addr), addr&0xFF00)
+		return c.ROM[offset : offset+0x100]
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		offset := c.ramOffset(c.ramBank, addr&0xFF00)
+		return c.RAM[offset : offset+0x100]
+	}
+	return nil
+}
+
+// offset wraps bank around the banks present, like unconnected address
+// lines on a real board.
+func (c *Cartridge) offset(bank int, addr uint16) int {
+	n := len(c.ROM) / BankSize
+	bank = (bank%n + n) % n
+	return bank*BankSize + int(addr%BankSize)
+}
+
+// ramOffset is offset for RAM: bank wraps around the RAM banks present.
+func (c *Cartridge) ramOffset(bank int, addr uint16) int {
+	n := len(c.RAM) / RAMBankSize
+	bank = (bank%n + n) % n
+	return bank*RAMBankSize + int(addr%RAMBankSize)
+}
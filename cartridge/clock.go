@@ -0,0 +1,45 @@
+package cartridge
+
+import "time"
+
+// Clock is the time source behind a cartridge real-time clock.
+// HostClock follows the wall clock; CycleClock follows emulated time, which
+// keeps tests and replays deterministic and makes fast-forward age the RTC.
+type Clock interface {
+	Now() time.Time
+}
+
+// HostClock reads the host's wall clock.
+type HostClock struct{}
+
+func (HostClock) Now() time.Time {
+	return time.Now()
+}
+
+// CPUFrequency is the DMG clock in T-cycles per second.
+const CPUFrequency = 4194304
+
+// CycleClock turns emulated T-cycles into time. It starts at Start and only
+// moves when Tick is called.
+type CycleClock struct {
+	Start  time.Time
+	cycles uint64
+}
+
+// NewCycleClock returns a CycleClock starting at start.
+func NewCycleClock(start time.Time) *CycleClock {
+	return &CycleClock{Start: start}
+}
+
+// Tick advances the clock by cycles T-cycles at CPUFrequency. In CGB double
+// speed mode, pass half the CPU cycles: the RTC crystal doesn't speed up.
+func (c *CycleClock) Tick(cycles int) {
+	c.cycles += uint64(cycles)
+}
+
+func (c *CycleClock) Now() time.Time {
+	// split to avoid overflowing cycles*1e9 after ~36 minutes
+	secs := c.cycles / CPUFrequency
+	nanos := c.cycles % CPUFrequency * uint64(time.Second) / CPUFrequency
+	return c.Start.Add(time.Duration(secs)*time.Second + time.Duration(nanos))
+}
@@ -0,0 +1,195 @@
+package cartridge
+
+// 93LC56 Serial EEPROM
+// -----------------------------
+// 128 words of 16 bits behind a Microwire interface: the game bit-bangs
+// CS, CLK and DI and samples DO. While CS is high, every rising CLK edge
+// shifts one bit in. A command is a start bit (1), a 2-bit opcode and an
+// 8-bit address (the top bit is ignored on the 128-word part):
+//
+//	10 AAAAAAAA          READ   DO: dummy 0, then 16 bits MSB first,
+//	                            then the next word (sequential read)
+//	01 AAAAAAAA DDDD...  WRITE  16 data bits follow
+//	11 AAAAAAAA          ERASE  word = 0xFFFF
+//	00 11xxxxxx          EWEN   enable writes/erases
+//	00 00xxxxxx          EWDS   disable writes/erases (power-on state)
+//	00 10xxxxxx          ERAL   erase everything
+//	00 01xxxxxx DDDD...  WRAL   write everything
+//
+// Writes complete instantly here, so DO reads back 1 (ready) after them.
+// Source: https://gbdev.io/pandocs/MBC7.html
+// -----------------------------
+
+const (
+	eepromWords = 128
+	eepromSize  = eepromWords * 2
+)
+
+type eepromState int
+
+const (
+	eepromIdle    eepromState = iota // waiting for a start bit
+	eepromCommand                    // shifting in opcode + address
+	eepromRead                       // shifting data out
+	eepromData                       // shifting in 16 data bits
+)
+
+// eeprom keeps its words in data, stored little-endian so the cartridge
+// can persist it as plain save RAM.
+type eeprom struct {
+	data []byte
+
+	cs, clk, di, do bool
+	writeEnabled    bool
+
+	state   eepromState
+	shift   uint16
+	bits    int
+	opcode  byte
+	address byte
+}
+
+func newEEPROM(data []byte) *eeprom {
+	return &eeprom{data: data, do: true}
+}
+
+func (e *eeprom) word(addr byte) uint16 {
+	i := int(addr&(eepromWords-1)) * 2
+	return uint16(e.data[i]) | uint16(e.data[i+1])<<8
+}
+
+func (e *eeprom) setWord(addr byte, value uint16) {
+	i := int(addr&(eepromWords-1)) * 2
+	e.data[i] = byte(value)
+	e.data[i+1] = byte(value >> 8)
+}
+
+// read returns the pin register: CS bit 7, CLK bit 6, DI bit 1, DO bit 0.
+func (e *eeprom) read() byte {
+	var v byte
+	if e.cs {
+		v |= 0x80
+	}
+	if e.clk {
+		v |= 0x40
+	}
+	if e.di {
+		v |= 0x02
+	}
+	if e.do {
+		v |= 0x01
+	}
+	return v
+}
+
+func (e *eeprom) write(data byte) {
+	cs, clk, di := data&0x80 != 0, data&0x40 != 0, data&0x02 != 0
+	rising := clk && !e.clk
+	e.cs, e.clk, e.di = cs, clk, di
+
+	if !cs {
+		// dropping CS aborts whatever was in progress
+		e.state = eepromIdle
+		return
+	}
+	if rising {
+		e.clock(di)
+	}
+}
+
+// clock handles one rising CLK edge with CS high.
+func (e *eeprom) clock(di bool) {
+	switch e.state {
+	case eepromIdle:
+		if di {
+			e.state = eepromCommand
+			e.shift, e.bits = 0, 0
+		}
+
+	case eepromCommand:
+		e.shiftIn(di)
+		if e.bits == 10 {
+			e.opcode = byte(e.shift >> 8)
+			e.address = byte(e.shift)
+			e.command()
+		}
+
+	case eepromData:
+		e.shiftIn(di)
+		if e.bits == 16 {
+			e.finishWrite(e.shift)
+			e.state = eepromIdle
+		}
+
+	case eepromRead:
+		e.do = e.shift&0x8000 != 0
+		e.shift <<= 1
+		e.bits++
+		if e.bits == 16 {
+			e.address++
+			e.shift, e.bits = e.word(e.address), 0
+		}
+	}
+}
+
+func (e *eeprom) shiftIn(di bool) {
+	e.shift <<= 1
+	if di {
+		e.shift |= 1
+	}
+	e.bits++
+}
+
+// command runs once opcode and address are in.
+func (e *eeprom) command() {
+	e.state = eepromIdle
+	switch e.opcode {
+	case 0b10: // READ
+		e.do = false // dummy bit
+		e.shift, e.bits = e.word(e.address), 0
+		e.state = eepromRead
+
+	case 0b01: // WRITE
+		e.shift, e.bits = 0, 0
+		e.state = eepromData
+
+	case 0b11: // ERASE
+		if e.writeEnabled {
+			e.setWord(e.address, 0xFFFF)
+		}
+		e.do = true
+
+	case 0b00:
+		switch e.address >> 6 {
+		case 0b11: // EWEN
+			e.writeEnabled = true
+		case 0b00: // EWDS
+			e.writeEnabled = false
+		case 0b10: // ERAL
+			if e.writeEnabled {
+				for addr := 0; addr < eepromWords; addr++ {
+					e.setWord(byte(addr), 0xFFFF)
+				}
+			}
+			e.do = true
+		case 0b01: // WRAL
+			e.shift, e.bits = 0, 0
+			e.state = eepromData
+		}
+	}
+}
+
+// finishWrite stores the 16 data bits of a WRITE or WRAL.
+func (e *eeprom) finishWrite(value uint16) {
+	e.do = true
+	if !e.writeEnabled {
+		return
+	}
+	if e.opcode == 0b00 { // WRAL
+		for addr := 0; addr < eepromWords; addr++ {
+			e.setWord(byte(addr), value)
+		}
+		return
+	}
+	e.setWord(e.address, value)
+}
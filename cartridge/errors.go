@@ -0,0 +1,19 @@
+package cartridge
+
+import "errors"
+
+// Loader errors. The returned errors wrap one of these with the details, so
+// callers can test with errors.Is.
+var (
+	ErrTruncated      = errors.New("ROM image is truncated")
+	ErrInconsistent   = errors.New("ROM header is inconsistent")
+	ErrBadLogo        = errors.New("ROM logo does not match, the boot ROM would lock up")
+	ErrHeaderChecksum = errors.New("header checksum mismatch")
+	ErrGlobalChecksum = errors.New("global checksum mismatch")
+	ErrUnknownType    = errors.New("unknown cartridge type")
+	ErrInvalidSize    = errors.New("invalid size code")
+	ErrUnsupportedMBC = errors.New("memory bank controller not supported")
+	ErrSaveSize       = errors.New("save file size does not match the cartridge")
+	ErrNoROM          = errors.New("no ROM in archive")
+	ErrROMTooLarge    = errors.New("ROM image is larger than any cartridge")
+)
@@ -0,0 +1,352 @@
+package cartridge
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Cartridge Header Layout
+// Source: https://gbdev.io/pandocs/The_Cartridge_Header.html
+const (
+	EntryPointAddr     = 0x0100
+	LogoAddr           = 0x0104 // 0x0104-0x0133
+	TitleAddr          = 0x0134 // 0x0134-0x0143 (16 bytes on old carts)
+	ManufacturerAddr   = 0x013F // 0x013F-0x0142, new carts only
+	CGBFlagAddr        = 0x0143
+	NewLicenseeAddr    = 0x0144 // 0x0144-0x0145
+	SGBFlagAddr        = 0x0146
+	TypeAddr           = 0x0147
+	ROMSizeAddr        = 0x0148
+	RAMSizeAddr        = 0x0149
+	DestinationAddr    = 0x014A
+	OldLicenseeAddr    = 0x014B
+	VersionAddr        = 0x014C
+	HeaderChecksumAddr = 0x014D
+	GlobalChecksumAddr = 0x014E // 0x014E-0x014F, big-endian
+	HeaderEnd          = 0x014F
+)
+
+// Bank sizes
+const (
+	ROMBankSize = 0x4000
+	RAMBankSize = 0x2000
+)
+
+const (
+	minimumROMSize      = 2 * ROMBankSize
+	useNewLicenseeCode  = 0x33
+	headerChecksumStart = TitleAddr
+	headerChecksumEnd   = VersionAddr
+)
+
+// Logo is the bitmap the boot ROM compares against 0x0104-0x0133.
+// A cartridge without it never gets past the boot screen.
+var Logo = [48]byte{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83, 0x00, 0x0C, 0x00, 0x0D,
+	0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E, 0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99,
+	0xBB, 0xBB, 0x67, 0x63, 0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+// CGB flag values (0x0143)
+const (
+	CGBSupported = 0x80 // works on DMG too
+	CGBOnly      = 0xC0
+)
+
+// SGBSupported is the SGB flag value (0x0146) enabling Super Game Boy functions.
+const SGBSupported = 0x03
+
+// MBC is the memory bank controller family wired into a cartridge.
+type MBC int
+
+const (
+	ROMOnly MBC = iota
+	MBC1
+	MBC2
+	MBC3
+	MBC5
+	MBC6
+	MBC7
+	MMM01
+	PocketCamera
+	TAMA5
+	HuC1
+	HuC3
+)
+
+var mbcNames = [...]string{
+	ROMOnly:      "ROM only",
+	MBC1:         "MBC1",
+	MBC2:         "MBC2",
+	MBC3:         "MBC3",
+	MBC5:         "MBC5",
+	MBC6:         "MBC6",
+	MBC7:         "MBC7",
+	MMM01:        "MMM01",
+	PocketCamera: "Pocket Camera",
+	TAMA5:        "Bandai TAMA5",
+	HuC1:         "HuC1",
+	HuC3:         "HuC3",
+}
+
+func (m MBC) String() string {
+	if int(m) < len(mbcNames) {
+		return mbcNames[m]
+	}
+	return fmt.Sprintf("MBC(%d)", int(m))
+}
+
+// Type describes what the cartridge type byte (0x0147) says is on the board.
+type Type struct {
+	Code    byte
+	MBC     MBC
+	RAM     bool
+	Battery bool
+	Timer   bool
+	Rumble  bool
+	Sensor  bool
+}
+
+func (t Type) String() string {
+	parts := []string{t.MBC.String()}
+	if t.Timer {
+		parts = append(parts, "TIMER")
+	}
+	if t.Rumble {
+		parts = append(parts, "RUMBLE")
+	}
+	if t.Sensor {
+		parts = append(parts, "SENSOR")
+	}
+	if t.RAM {
+		parts = append(parts, "RAM")
+	}
+	if t.Battery {
+		parts = append(parts, "BATTERY")
+	}
+	return strings.Join(parts, "+")
+}
+
+// types maps 0x0147 to the board description.
+var types = map[byte]Type{
+	0x00: {MBC: ROMOnly},
+	0x01: {MBC: MBC1},
+	0x02: {MBC: MBC1, RAM: true},
+	0x03: {MBC: MBC1, RAM: true, Battery: true},
+	0x05: {MBC: MBC2},
+	0x06: {MBC: MBC2, Battery: true},
+	0x08: {MBC: ROMOnly, RAM: true},
+	0x09: {MBC: ROMOnly, RAM: true, Battery: true},
+	0x0B: {MBC: MMM01},
+	0x0C: {MBC: MMM01, RAM: true},
+	0x0D: {MBC: MMM01, RAM: true, Battery: true},
+	0x0F: {MBC: MBC3, Timer: true, Battery: true},
+	0x10: {MBC: MBC3, Timer: true, RAM: true, Battery: true},
+	0x11: {MBC: MBC3},
+	0x12: {MBC: MBC3, RAM: true},
+	0x13: {MBC: MBC3, RAM: true, Battery: true},
+	0x19: {MBC: MBC5},
+	0x1A: {MBC: MBC5, RAM: true},
+	0x1B: {MBC: MBC5, RAM: true, Battery: true},
+	0x1C: {MBC: MBC5, Rumble: true},
+	0x1D: {MBC: MBC5, Rumble: true, RAM: true},
+	0x1E: {MBC: MBC5, Rumble: true, RAM: true, Battery: true},
+	0x20: {MBC: MBC6},
+	0x22: {MBC: MBC7, Sensor: true, Rumble: true, RAM: true, Battery: true},
+	0xFC: {MBC: PocketCamera, RAM: true, Battery: true},
+	0xFD: {MBC: TAMA5},
+	0xFE: {MBC: HuC3, Timer: true, RAM: true, Battery: true},
+	0xFF: {MBC: HuC1, RAM: true, Battery: true},
+}
+
+// ramSizes maps 0x0149 to the external RAM size in bytes.
+// 0x01 (2KiB) was never used officially but shows up in homebrew.
+var ramSizes = map[byte]int{
+	0x00: 0,
+	0x01: 2 * 1024,
+	0x02: 8 * 1024,
+	0x03: 32 * 1024,
+	0x04: 128 * 1024,
+	0x05: 64 * 1024,
+}
+
+// Header is the parsed cartridge header (0x0100-0x014F).
+type Header struct {
+	Title            string
+	ManufacturerCode string // empty on carts that predate it
+	CGBFlag          byte
+	SGBFlag          byte
+	Type             Type
+	ROMSize          int // bytes
+	RAMSize          int // bytes, as declared (MBC2/MBC7 have their RAM built in and declare 0)
+	Destination      byte
+	OldLicensee      byte
+	NewLicensee      string
+	Version          byte
+	HeaderChecksum   byte
+	GlobalChecksum   uint16
+}
+
+// CGB reports whether the cartridge enables CGB functions.
+func (h *Header) CGB() bool {
+	return h.CGBFlag&0x80 != 0
+}
+
+// CGBOnly reports whether the cartridge refuses to run on a DMG.
+func (h *Header) CGBOnly() bool {
+	return h.CGBFlag == CGBOnly
+}
+
+// SGB reports whether the cartridge enables Super Game Boy functions.
+// The SGB only honours the flag when the old licensee code is 0x33.
+func (h *Header) SGB() bool {
+	return h.SGBFlag == SGBSupported && h.OldLicensee == useNewLicenseeCode
+}
+
+// Licensee returns the publisher code: the two-character new licensee code
+// when 0x014B is 0x33, otherwise the old code as two hex digits.
+func (h *Header) Licensee() string {
+	if h.OldLicensee == useNewLicenseeCode {
+		return h.NewLicensee
+	}
+	return fmt.Sprintf("%02X", h.OldLicensee)
+}
+
+// ROMBanks returns the number of 16KiB ROM banks.
+func (h *Header) ROMBanks() int {
+	return h.ROMSize / ROMBankSize
+}
+
+// ParseHeader decodes and validates the header of rom.
+// It checks the logo, the header checksum, the size codes and the cartridge
+// type, and that rom is at least as large as the header says. Overdumps,
+// images larger than the declared ROM, are common and run fine on hardware,
+// so they are accepted; New drops the extra bytes. The global checksum is
+// checked separately by VerifyGlobalChecksum.
+func ParseHeader(rom []byte) (*Header, error) {
+	return parseHeaderAt(rom, 0)
+}
+
+// parseHeaderAt parses the header of the 32KiB block starting at base.
+// Only MMM01 multicarts keep their real header anywhere but base 0.
+func parseHeaderAt(rom []byte, base int) (*Header, error) {
+	if len(rom) <= base+HeaderEnd {
+		return nil, fmt.Errorf("%w: %d bytes, the header alone needs %d", ErrTruncated, len(rom), base+HeaderEnd+1)
+	}
+	hdr := rom[base:]
+	if !bytes.Equal(hdr[LogoAddr:LogoAddr+len(Logo)], Logo[:]) {
+		return nil, ErrBadLogo
+	}
+	if sum := HeaderChecksum(hdr); sum != hdr[HeaderChecksumAddr] {
+		return nil, fmt.Errorf("%w: header says 0x%02X, computed 0x%02X", ErrHeaderChecksum, hdr[HeaderChecksumAddr], sum)
+	}
+
+	h := &Header{
+		CGBFlag:        hdr[CGBFlagAddr],
+		SGBFlag:        hdr[SGBFlagAddr],
+		NewLicensee:    string(hdr[NewLicenseeAddr : NewLicenseeAddr+2]),
+		Destination:    hdr[DestinationAddr],
+		OldLicensee:    hdr[OldLicenseeAddr],
+		Version:        hdr[VersionAddr],
+		HeaderChecksum: hdr[HeaderChecksumAddr],
+		GlobalChecksum: uint16(hdr[GlobalChecksumAddr])<<8 | uint16(hdr[GlobalChecksumAddr+1]),
+	}
+	h.Title, h.ManufacturerCode = parseTitle(hdr)
+
+	var ok bool
+	code := hdr[TypeAddr]
+	if h.Type, ok = types[code]; !ok {
+		return nil, fmt.Errorf("%w: type 0x%02X", ErrUnknownType, code)
+	}
+	h.Type.Code = code
+
+	sizeCode := hdr[ROMSizeAddr]
+	if sizeCode > 0x08 {
+		return nil, fmt.Errorf("%w: ROM size code 0x%02X", ErrInvalidSize, sizeCode)
+	}
+	h.ROMSize = minimumROMSize << sizeCode
+
+	if h.RAMSize, ok = ramSizes[hdr[RAMSizeAddr]]; !ok {
+		return nil, fmt.Errorf("%w: RAM size code 0x%02X", ErrInvalidSize, hdr[RAMSizeAddr])
+	}
+
+	if err := h.checkConsistency(len(rom)); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// checkConsistency compares the header's claims with each other and with
+// the image size. Only a short image is an error, see ParseHeader.
+func (h *Header) checkConsistency(imageSize int) error {
+	if imageSize < h.ROMSize {
+		return fmt.Errorf("%w: header declares %d KiB of ROM, image has %d bytes", ErrTruncated, h.ROMSize/1024, imageSize)
+	}
+	if h.RAMSize > 0 && !h.Type.RAM {
+		return fmt.Errorf("%w: type 0x%02X (%s) has no RAM but declares %d KiB", ErrInconsistent, h.Type.Code, h.Type, h.RAMSize/1024)
+	}
+	return nil
+}
+
+// parseTitle splits 0x0134-0x0143 into title and manufacturer code.
+// Newer carts shortened the title to 11 bytes to fit the manufacturer code
+// and CGB flag; older carts use all 16 bytes for the title.
+func parseTitle(rom []byte) (title, manufacturer string) {
+	raw := rom[TitleAddr : CGBFlagAddr+1]
+	if rom[CGBFlagAddr]&0x80 != 0 {
+		raw = raw[:CGBFlagAddr-TitleAddr]
+		if code := rom[ManufacturerAddr:CGBFlagAddr]; isManufacturerCode(code) {
+			manufacturer = string(code)
+			raw = raw[:ManufacturerAddr-TitleAddr]
+		}
+	}
+	if i := bytes.IndexByte(raw, 0); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimRight(string(raw), " "), manufacturer
+}
+
+func isManufacturerCode(code []byte) bool {
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// HeaderChecksum computes the checksum the boot ROM verifies against 0x014D.
+func HeaderChecksum(rom []byte) byte {
+	var sum byte
+	for addr := headerChecksumStart; addr <= headerChecksumEnd; addr++ {
+		sum = sum - rom[addr] - 1
+	}
+	return sum
+}
+
+// GlobalChecksum computes the 16-bit sum of every ROM byte except the two
+// checksum bytes themselves.
+func GlobalChecksum(rom []byte) uint16 {
+	var sum uint16
+	for i, b := range rom {
+		if i != GlobalChecksumAddr && i != GlobalChecksumAddr+1 {
+			sum += uint16(b)
+		}
+	}
+	return sum
+}
+
+// VerifyGlobalChecksum compares the global checksum stored in the header
+// with the image. Real hardware never checks it, so callers may choose to
+// ignore ErrGlobalChecksum.
+func VerifyGlobalChecksum(rom []byte) error {
+	if len(rom) <= HeaderEnd {
+		return fmt.Errorf("%w: %d bytes, the header alone needs %d", ErrTruncated, len(rom), HeaderEnd+1)
+	}
+	stored := uint16(rom[GlobalChecksumAddr])<<8 | uint16(rom[GlobalChecksumAddr+1])
+	if sum := GlobalChecksum(rom); sum != stored {
+		return fmt.Errorf("%w: header says 0x%04X, computed 0x%04X", ErrGlobalChecksum, stored, sum)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cartridge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+func TestParseHeader_Fields(t *testing.T) {
+	rom := carttest.Builder{Type: 0x1B, ROMSize: 0x02, RAMSize: 0x03}.Build()
+	copy(rom[TitleAddr:], "POKEMON_GLDAAUE\xC0")
+	rom[NewLicenseeAddr], rom[NewLicenseeAddr+1] = '0', '1'
+	rom[SGBFlagAddr] = SGBSupported
+	rom[OldLicenseeAddr] = 0x33
+	rom[VersionAddr] = 0x01
+	carttest.Fix(rom)
+
+	h, err := ParseHeader(rom)
+	if err != nil {
+		t.Fatalf("ParseHeader() error: %v", err)
+	}
+
+	if h.Title != "POKEMON_GLD" || h.ManufacturerCode != "AAUE" {
+		t.Errorf("Title, Manufacturer = %q, %q; want POKEMON_GLD, AAUE", h.Title, h.ManufacturerCode)
+	}
+	if !h.CGB() || !h.CGBOnly() || !h.SGB() {
+		t.Errorf("CGB %v CGBOnly %v SGB %v; want all true", h.CGB(), h.CGBOnly(), h.SGB())
+	}
+	if h.Type.MBC != MBC5 || !h.Type.RAM || !h.Type.Battery || h.Type.String() != "MBC5+RAM+BATTERY" {
+		t.Errorf("Type = %+v (%s); want MBC5+RAM+BATTERY", h.Type, h.Type)
+	}
+	if h.ROMSize != 128*1024 || h.ROMBanks() != 8 || h.RAMSize != 32*1024 {
+		t.Errorf("ROMSize %d banks %d RAMSize %d; want 128KiB, 8, 32KiB", h.ROMSize, h.ROMBanks(), h.RAMSize)
+	}
+	if h.Licensee() != "01" || h.Version != 1 {
+		t.Errorf("Licensee %q Version %d; want 01, 1", h.Licensee(), h.Version)
+	}
+}
+
+func TestParseHeader_OldTitle(t *testing.T) {
+	rom := carttest.Builder{}.Build()
+	copy(rom[TitleAddr:], "SUPER MARIOLAND\x00")
+	rom[OldLicenseeAddr] = 0x01
+	carttest.Fix(rom)
+
+	h, err := ParseHeader(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Title != "SUPER MARIOLAND" || h.ManufacturerCode != "" || h.Licensee() != "01" {
+		t.Errorf("Title %q Manufacturer %q Licensee %q", h.Title, h.ManufacturerCode, h.Licensee())
+	}
+}
+
+func TestParseHeader_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		rom  func() []byte
+		want error
+	}{
+		{"Shorter than header", func() []byte { return make([]byte, 0x100) }, ErrTruncated},
+		{"Missing logo", func() []byte {
+			rom := carttest.Builder{}.Build()
+			rom[LogoAddr] = 0
+			return rom
+		}, ErrBadLogo},
+		{"Header checksum", func() []byte {
+			rom := carttest.Builder{}.Build()
+			rom[HeaderChecksumAddr]++
+			return rom
+		}, ErrHeaderChecksum},
+		{"Unknown type", func() []byte {
+			rom := carttest.Builder{Type: 0x42}.Build()
+			return rom
+		}, ErrUnknownType},
+		{"ROM size code", func() []byte {
+			rom := carttest.Builder{}.Build()
+			rom[ROMSizeAddr] = 0x52
+			carttest.Fix(rom)
+			return rom
+		}, ErrInvalidSize},
+		{"RAM size code", func() []byte { return carttest.Builder{Type: 0x03, RAMSize: 0x09}.Build() }, ErrInvalidSize},
+		{"Image smaller than declared", func() []byte {
+			rom := carttest.Builder{Type: 0x01, ROMSize: 0x02}.Build()
+			return rom[:64*1024]
+		}, ErrTruncated},
+		{"RAM on a type without RAM", func() []byte { return carttest.Builder{Type: 0x01, RAMSize: 0x02}.Build() }, ErrInconsistent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseHeader(tt.rom())
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ParseHeader() error = %v; want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyGlobalChecksum(t *testing.T) {
+	rom := carttest.Builder{}.Build()
+	if err := VerifyGlobalChecksum(rom); err != nil {
+		t.Fatalf("fresh ROM: %v", err)
+	}
+	rom[0x2000] = 0x42
+	if err := VerifyGlobalChecksum(rom); !errors.Is(err, ErrGlobalChecksum) {
+		t.Errorf("edited ROM: error = %v; want ErrGlobalChecksum", err)
+	}
+}
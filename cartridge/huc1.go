@@ -0,0 +1,91 @@
+package cartridge
+
+// HuC1
+// -----------------------------
+// Hudson's MBC1 look-alike with an IR port instead of a RAM enable:
+//   0x0000-0x1FFF  0x0E maps the IR register at 0xA000-0xBFFF,
+//                  anything else maps RAM (always enabled)
+//   0x2000-0x3FFF  ROM bank, 6 bits
+//   0x4000-0x5FFF  RAM bank, 2 bits
+//   0x6000-0x7FFF  no effect
+// Source: https://gbdev.io/pandocs/HuC1.html
+// -----------------------------
+
+const hucIRMode = 0x0E
+
+type huc1 struct {
+	banks
+	irMode  bool
+	romBank byte
+	ramBank byte
+	ir      irRegister
+}
+
+func newHuC1(rom []byte, h *Header, port InfraredPort) *huc1 {
+	return &huc1{banks: newBanks(rom, h.RAMSize), romBank: 1, ir: irRegister{port: port}}
+}
+
+func (c *huc1) Read(addr uint16) byte {
+	if addr >= 0xA000 && addr <= 0xBFFF && c.irMode {
+		return c.ir.read()
+	}
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *huc1) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.irMode = data&0x0F == hucIRMode
+	case addr <= 0x3FFF:
+		c.romBank = data & 0x3F
+	case addr <= 0x5FFF:
+		c.ramBank = data & 0x03
+	case addr <= 0x7FFF:
+		// no register here
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if c.irMode {
+			c.ir.write(data)
+			return
+		}
+		c.setRAMByte(int(c.ramBank), addr, data)
+	}
+}
+
+func (c *huc1) Bank(addr uint16) int {
+	switch {
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return int(c.romBank)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return int(c.ramBank)
+	}
+	return 0
+}
+
+func (c *huc1) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *huc1) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+func (c *huc1) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && !c.irMode:
+		return c.ramPage(int(c.ramBank), addr)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+// fakeIR records LED changes and reports a settable light level.
+type fakeIR struct {
+	light bool
+	leds  []bool
+}
+
+func (p *fakeIR) SetLED(on bool) { p.leds = append(p.leds, on) }
+func (p *fakeIR) Light() bool    { return p.light }
+
+func TestHuC1_Banking(t *testing.T) {
+	rom := carttest.Builder{Type: 0xFF, ROMSize: 0x05, RAMSize: 0x03}.Build() // 1MiB, 32KiB RAM
+	markBanks(rom)
+	carttest.Fix(rom)
+	mmu := newTestMMU(t, rom)
+
+	mmu.Write(0x2000, 0x3F)
+	if got := mmu.Read(0x4200); got != 0x3F {
+		t.Errorf("ROM bank 0x3F, Read(0x4200) = 0x%X", got)
+	}
+	mmu.Write(0x2000, 0x00) // no 0 -> 1 fix on HuC1
+	if got := mmu.Read(0x4200); got != 0x00 {
+		t.Errorf("ROM bank 0, Read(0x4200) = 0x%X", got)
+	}
+
+	for bank := byte(0); bank < 4; bank++ {
+		mmu.Write(0x4000, bank)
+		mmu.Write(0xA000, 0x10|bank)
+	}
+	mmu.Write(0x4000, 0x02)
+	if got := mmu.Read(0xA000); got != 0x12 {
+		t.Errorf("RAM bank 2 = 0x%X; want 0x12", got)
+	}
+}
+
+func TestHuC1_Infrared(t *testing.T) {
+	port := &fakeIR{}
+	cart, err := NewWithOptions(carttest.Builder{Type: 0xFF, ROMSize: 0x01, RAMSize: 0x02}.Build(), Options{Infrared: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mmu := memory.NewMMU(cart)
+	mmu.Write(0xA000, 0x55)
+
+	mmu.Write(0x0000, 0x0E)
+	if got := mmu.Read(0xA000); got != 0xC0 {
+		t.Errorf("IR dark = 0x%X; want 0xC0", got)
+	}
+	port.light = true
+	if got := mmu.Read(0xA000); got != 0xC1 {
+		t.Errorf("IR light = 0x%X; want 0xC1", got)
+	}
+	mmu.Write(0xA000, 0x01)
+	mmu.Write(0xA000, 0x01)
+	mmu.Write(0xA000, 0x00)
+	if len(port.leds) != 2 || !port.leds[0] || port.leds[1] {
+		t.Errorf("LED events = %v; want [true false]", port.leds)
+	}
+
+	mmu.Write(0x0000, 0x00)
+	if got := mmu.Read(0xA000); got != 0x55 {
+		t.Errorf("IR write reached RAM, Read(0xA000) = 0x%X; want 0x55", got)
+	}
+}
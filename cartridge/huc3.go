@@ -0,0 +1,225 @@
+package cartridge
+
+import "time"
+
+// HuC3
+// -----------------------------
+// 0x0000-0x1FFF selects what 0xA000-0xBFFF is connected to:
+//   0x0  RAM, read only          0xA  RAM, read/write
+//   0xB  RTC command (write)     0xC  RTC response (read)
+//   0xD  RTC semaphore (read 1 = ready)
+//   0xE  IR register (same as HuC1)
+// 0x2000-0x3FFF is the 7-bit ROM bank, 0x4000-0x5FFF the RAM bank.
+//
+// The RTC is a small MCU with 256 nibbles of memory. A command byte has
+// the command in the upper nibble and its argument in the lower one:
+//   0x1  read memory[addr] into the response, addr++
+//   0x3  write arg to memory[addr], addr++
+//   0x4  addr low nibble = arg      0x5  addr high nibble = arg
+//   0x6  extended: arg 0x0 copies the time to memory 0x00-0x05,
+//        arg 0x1 sets the time from memory 0x00-0x05,
+//        arg 0x2 reports status (response nibble 1),
+//        arg 0xE plays the tone stored in memory 0x27
+// The time is minute-of-day (12 bits, nibbles 0-2) and day counter
+// (12 bits, nibbles 3-5), least significant nibble first.
+// Responses read back as 0x80 | command<<4 | nibble.
+// Source: https://gbdev.io/pandocs/HuC3.html
+// -----------------------------
+
+const (
+	huc3RAMRead      = 0x0
+	huc3RAMReadWrite = 0xA
+	huc3Command      = 0xB
+	huc3Response     = 0xC
+	huc3Semaphore    = 0xD
+	huc3IR           = 0xE
+
+	huc3ToneAddr    = 0x27
+	minutesPerDay   = 24 * 60
+	huc3TimeNibbles = 6
+)
+
+type huc3 struct {
+	banks
+	mode    byte
+	romBank byte
+	ramBank byte
+	ir      irRegister
+	rtc     *huc3RTC
+}
+
+// huc3RTC is the clock MCU. Only minutes and days are kept, like the chip.
+type huc3RTC struct {
+	clock   Clock
+	last    time.Time
+	minutes int // minute of the day
+	days    int // 12-bit day counter
+
+	memory   [256]byte
+	address  byte
+	response byte
+	onTone   func(tone byte)
+
+	// written is set when the game sets the time, see rtc.written.
+	written bool
+}
+
+func newHuC3(rom []byte, h *Header, clock Clock, port InfraredPort, onTone func(tone byte)) *huc3 {
+	if clock == nil {
+		clock = HostClock{}
+	}
+	return &huc3{
+		banks:   newBanks(rom, h.RAMSize),
+		romBank: 1,
+		ir:      irRegister{port: port},
+		rtc:     &huc3RTC{clock: clock, last: clock.Now(), onTone: onTone},
+	}
+}
+
+func (c *huc3) Read(addr uint16) byte {
+	if addr < 0xA000 || addr > 0xBFFF {
+		return c.PeekBank(c.Bank(addr), addr)
+	}
+	switch c.mode {
+	case huc3RAMRead, huc3RAMReadWrite:
+		return c.ramByte(int(c.ramBank), addr)
+	case huc3Response:
+		return c.rtc.response
+	case huc3Semaphore:
+		return 0x01 // commands complete instantly
+	case huc3IR:
+		return c.ir.read()
+	}
+	return 0xFF
+}
+
+func (c *huc3) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.mode = data & 0x0F
+	case addr <= 0x3FFF:
+		c.romBank = data & 0x7F
+	case addr <= 0x5FFF:
+		c.ramBank = data & 0x03
+	case addr <= 0x7FFF:
+		// no register here
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		switch c.mode {
+		case huc3RAMReadWrite:
+			c.setRAMByte(int(c.ramBank), addr, data)
+		case huc3Command:
+			c.rtc.command(data)
+		case huc3IR:
+			c.ir.write(data)
+		}
+	}
+}
+
+// update advances minutes and days to the clock's current time, keeping
+// the unconsumed fraction of a minute for later.
+func (r *huc3RTC) update() {
+	now := r.clock.Now()
+	elapsed := now.Sub(r.last)
+	if elapsed < 0 {
+		r.last = now
+		return
+	}
+	minutes := int64(elapsed / time.Minute)
+	r.last = r.last.Add(time.Duration(minutes) * time.Minute)
+	total := int64(r.minutes) + minutes
+	r.days = int((int64(r.days) + total/minutesPerDay) & 0xFFF)
+	r.minutes = int(total % minutesPerDay)
+}
+
+func (r *huc3RTC) command(data byte) {
+	cmd, arg := data>>4&0x07, data&0x0F
+	switch cmd {
+	case 0x1:
+		r.respond(cmd, r.memory[r.address]&0x0F)
+		r.address++
+	case 0x3:
+		r.memory[r.address] = arg
+		r.address++
+		r.respond(cmd, arg)
+	case 0x4:
+		r.address = r.address&0xF0 | arg
+		r.respond(cmd, arg)
+	case 0x5:
+		r.address = r.address&0x0F | arg<<4
+		r.respond(cmd, arg)
+	case 0x6:
+		r.extended(arg)
+	}
+}
+
+func (r *huc3RTC) respond(cmd, nibble byte) {
+	r.response = 0x80 | cmd<<4 | nibble
+}
+
+func (r *huc3RTC) extended(arg byte) {
+	switch arg {
+	case 0x0: // time -> memory
+		r.update()
+		value := uint32(r.minutes) | uint32(r.days)<<12
+		for i := 0; i < huc3TimeNibbles; i++ {
+			r.memory[i] = byte(value >> (4 * i) & 0x0F)
+		}
+	case 0x1: // memory -> time
+		var value uint32
+		for i := 0; i < huc3TimeNibbles; i++ {
+			value |= uint32(r.memory[i]&0x0F) << (4 * i)
+		}
+		r.minutes = int(value&0xFFF) % minutesPerDay
+		r.days = int(value >> 12 & 0xFFF)
+		r.last = r.clock.Now()
+		r.written = true
+	case 0x2:
+		r.respond(0x6, 0x1)
+		return
+	case 0xE:
+		if r.onTone != nil {
+			r.onTone(r.memory[huc3ToneAddr] & 0x0F)
+		}
+	}
+	r.respond(0x6, arg)
+}
+
+func (c *huc3) Bank(addr uint16) int {
+	switch {
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return int(c.romBank)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return int(c.ramBank)
+	}
+	return 0
+}
+
+func (c *huc3) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *huc3) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+// ReadPage maps RAM only in the two RAM modes.
+func (c *huc3) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && (c.mode == huc3RAMRead || c.mode == huc3RAMReadWrite):
+		return c.ramPage(int(c.ramBank), addr)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cartridge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+func newHuC3TestMMU(t *testing.T, opts Options) (*memory.MMU, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)}
+	opts.Clock = clock
+	cart, err := NewWithOptions(carttest.Builder{Type: 0xFE, ROMSize: 0x02, RAMSize: 0x03}.Build(), opts)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error: %v", err)
+	}
+	return memory.NewMMU(cart), clock
+}
+
+// huc3Do sends an RTC command and returns the response byte.
+func huc3Do(mmu *memory.MMU, cmd byte) byte {
+	mmu.Write(0x0000, 0x0B)
+	mmu.Write(0xA000, cmd)
+	mmu.Write(0x0000, 0x0C)
+	return mmu.Read(0xA000)
+}
+
+// huc3Seek points the RTC memory address at addr.
+func huc3Seek(mmu *memory.MMU, addr byte) {
+	huc3Do(mmu, 0x40|addr&0x0F)
+	huc3Do(mmu, 0x50|addr>>4)
+}
+
+func TestHuC3_ReadTime(t *testing.T) {
+	mmu, clock := newHuC3TestMMU(t, Options{})
+	clock.advance(24*time.Hour + 2*time.Hour + 3*time.Minute + 59*time.Second)
+
+	huc3Do(mmu, 0x60)
+	huc3Seek(mmu, 0x00)
+	want := []byte{0xB, 0x7, 0x0, 0x1, 0x0, 0x0} // 123 minutes, day 1
+	for i, w := range want {
+		if got := huc3Do(mmu, 0x10); got != 0x90|w {
+			t.Errorf("nibble %d = 0x%02X; want 0x%02X", i, got, 0x90|w)
+		}
+	}
+
+	mmu.Write(0x0000, 0x0D)
+	if got := mmu.Read(0xA000); got != 0x01 {
+		t.Errorf("semaphore = 0x%X; want 0x01", got)
+	}
+}
+
+func TestHuC3_SetTime(t *testing.T) {
+	mmu, clock := newHuC3TestMMU(t, Options{})
+
+	huc3Seek(mmu, 0x00)
+	for _, n := range []byte{0xF, 0x9, 0x5, 0x2, 0x0, 0x0} { // 23:59 (1439 minutes), day 2
+		huc3Do(mmu, 0x30|n)
+	}
+	huc3Do(mmu, 0x61)
+	clock.advance(time.Minute)
+
+	huc3Do(mmu, 0x60)
+	huc3Seek(mmu, 0x00)
+	want := []byte{0x0, 0x0, 0x0, 0x3, 0x0, 0x0}
+	for i, w := range want {
+		if got := huc3Do(mmu, 0x10) & 0x0F; got != w {
+			t.Errorf("nibble %d = 0x%X; want 0x%X", i, got, w)
+		}
+	}
+}
+
+func TestHuC3_RAMModes(t *testing.T) {
+	mmu, _ := newHuC3TestMMU(t, Options{})
+
+	mmu.Write(0x0000, 0x0A)
+	mmu.Write(0x4000, 0x01)
+	mmu.Write(0xA000, 0x42)
+
+	mmu.Write(0x0000, 0x00)
+	mmu.Write(0xA000, 0x24)
+	if got := mmu.Read(0xA000); got != 0x42 {
+		t.Errorf("read-only mode wrote RAM, Read(0xA000) = 0x%X; want 0x42", got)
+	}
+}
+
+func TestHuC3_Tone(t *testing.T) {
+	var tones []byte
+	mmu, _ := newHuC3TestMMU(t, Options{OnTone: func(tone byte) { tones = append(tones, tone) }})
+
+	huc3Seek(mmu, huc3ToneAddr)
+	huc3Do(mmu, 0x35)
+	huc3Do(mmu, 0x6E)
+	if len(tones) != 1 || tones[0] != 5 {
+		t.Errorf("tones = %v; want [5]", tones)
+	}
+}
@@ -0,0 +1,36 @@
+package cartridge
+
+// InfraredPort connects a cartridge's IR LED and light sensor (HuC1, HuC3)
+// to the outside world: another emulator instance, a link server, or a
+// test. Both methods run on the emulation goroutine.
+type InfraredPort interface {
+	// SetLED is called whenever the game turns its LED on or off.
+	SetLED(on bool)
+	// Light reports whether the sensor currently sees IR light.
+	Light() bool
+}
+
+// irRegister is the IR window shared by the Hudson mappers: reads return
+// 0xC0 with bit 0 set while light is seen, writes drive the LED with bit 0.
+type irRegister struct {
+	port InfraredPort
+	led  bool
+}
+
+func (r *irRegister) read() byte {
+	if r.port != nil && r.port.Light() {
+		return 0xC1
+	}
+	return 0xC0
+}
+
+func (r *irRegister) write(data byte) {
+	on := data&0x01 != 0
+	if on == r.led {
+		return
+	}
+	r.led = on
+	if r.port != nil {
+		r.port.SetLED(on)
+	}
+}
@@ -0,0 +1,145 @@
+package cartridge
+
+import "bytes"
+
+// MBC1
+// -----------------------------
+// Registers (write-only, selected by address):
+//   0x0000-0x1FFF  RAM enable: 0x?A enables, anything else disables
+//   0x2000-0x3FFF  BANK1: low 5 bits of the ROM bank, 0 is treated as 1
+//   0x4000-0x5FFF  BANK2: 2 more bits, upper ROM bits or RAM bank
+//   0x6000-0x7FFF  mode: 0 = BANK2 only affects 0x4000-0x7FFF,
+//                        1 = BANK2 also applies to 0x0000-0x3FFF and RAM
+//
+// The 0 -> 1 fix only looks at BANK1, so banks 0x20/0x40/0x60 can never be
+// mapped at 0x4000 (you get 0x21/0x41/0x61 instead).
+//
+// MBC1M multicarts (Mortal Kombat I & II, Bomberman Collection...) wire
+// BANK2 to ROM bits 4-5 instead of 5-6 and leave BANK1 bit 4 unconnected,
+// so each game sees its own 256KiB. They are 1MiB carts with a second
+// header (and so a second logo) at bank 0x10.
+// Source: https://gbdev.io/pandocs/MBC1.html
+// -----------------------------
+
+type mbc1 struct {
+	banks
+	ramEnabled bool
+	bank1      byte
+	bank2      byte
+	mode       byte
+
+	// bank2Shift is 5 on regular carts and 4 on MBC1M multicarts.
+	bank2Shift uint
+}
+
+func newMBC1(rom []byte, h *Header) *mbc1 {
+	c := &mbc1{banks: newBanks(rom, h.RAMSize), bank1: 1, bank2Shift: 5}
+	if isMBC1Multicart(rom) {
+		c.bank2Shift = 4
+	}
+	return c
+}
+
+// isMBC1Multicart detects MBC1M wiring: a 1MiB image with a logo at the
+// start of the second game (bank 0x10).
+func isMBC1Multicart(rom []byte) bool {
+	const multicartSize = 64 * ROMBankSize
+	if len(rom) != multicartSize {
+		return false
+	}
+	logo := 0x10*ROMBankSize + LogoAddr
+	return bytes.Equal(rom[logo:logo+len(Logo)], Logo[:])
+}
+
+// lowBank returns the ROM bank mapped at 0x0000-0x3FFF.
+func (c *mbc1) lowBank() int {
+	if c.mode == 0 {
+		return 0
+	}
+	return int(c.bank2) << c.bank2Shift
+}
+
+// highBank returns the ROM bank mapped at 0x4000-0x7FFF.
+func (c *mbc1) highBank() int {
+	bank1 := c.bank1
+	if c.bank2Shift == 4 {
+		bank1 &= 0x0F
+	}
+	return int(c.bank2)<<c.bank2Shift | int(bank1)
+}
+
+// ramBank returns the RAM bank mapped at 0xA000-0xBFFF.
+func (c *mbc1) ramBank() int {
+	if c.mode == 0 {
+		return 0
+	}
+	return int(c.bank2)
+}
+
+func (c *mbc1) Read(addr uint16) byte {
+	if addr >= 0xA000 && addr <= 0xBFFF && !c.ramEnabled {
+		return 0xFF
+	}
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *mbc1) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.ramEnabled = data&0x0F == 0x0A
+	case addr <= 0x3FFF:
+		c.bank1 = data & 0x1F
+		if c.bank1 == 0 {
+			c.bank1 = 1
+		}
+	case addr <= 0x5FFF:
+		c.bank2 = data & 0x03
+	case addr <= 0x7FFF:
+		c.mode = data & 0x01
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if c.ramEnabled {
+			c.setRAMByte(c.ramBank(), addr, data)
+		}
+	}
+}
+
+func (c *mbc1) Bank(addr uint16) int {
+	switch {
+	case addr <= 0x3FFF:
+		return c.lowBank()
+	case addr <= 0x7FFF:
+		return c.highBank()
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramBank()
+	}
+	return 0
+}
+
+func (c *mbc1) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *mbc1) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+func (c *mbc1) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && c.ramEnabled:
+		return c.ramPage(c.ramBank(), addr)
+	}
+	return nil
+}
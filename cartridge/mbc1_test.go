@@ -0,0 +1,129 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+// markBanks writes each bank's number at offset 0x0200 of that bank so tests
+// can see which bank is mapped.
+func markBanks(rom []byte) {
+	for bank := 0; bank < len(rom)/ROMBankSize; bank++ {
+		rom[bank*ROMBankSize+0x0200] = byte(bank)
+	}
+	carttest.Fix(rom)
+}
+
+func newTestMMU(t *testing.T, rom []byte) *memory.MMU {
+	t.Helper()
+	cart, err := New(rom)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	return memory.NewMMU(cart)
+}
+
+func TestMBC1_ROMBanking(t *testing.T) {
+	rom := carttest.Builder{Type: 0x01, ROMSize: 0x06}.Build() // 2MiB, 128 banks
+	markBanks(rom)
+	mmu := newTestMMU(t, rom)
+
+	tests := []struct {
+		name         string
+		bank1, bank2 byte
+		mode         byte
+		low, high    byte
+	}{
+		{"Power on", 0x01, 0x00, 0, 0x00, 0x01},
+		{"Bank 0 maps to 1", 0x00, 0x00, 0, 0x00, 0x01},
+		{"Bank 5", 0x05, 0x00, 0, 0x00, 0x05},
+		{"Only 5 bits", 0xE7, 0x00, 0, 0x00, 0x07},
+		{"Upper bits", 0x02, 0x01, 0, 0x00, 0x22},
+		{"0x20 maps to 0x21", 0x00, 0x01, 0, 0x00, 0x21},
+		{"Mode 1 moves 0x0000", 0x03, 0x02, 1, 0x40, 0x43},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mmu.Write(0x2000, tt.bank1)
+			mmu.Write(0x4000, tt.bank2)
+			mmu.Write(0x6000, tt.mode)
+			if got := mmu.Read(0x0200); got != tt.low {
+				t.Errorf("bank at 0x0000 = 0x%X; want 0x%X", got, tt.low)
+			}
+			if got := mmu.Read(0x4200); got != tt.high {
+				t.Errorf("bank at 0x4000 = 0x%X; want 0x%X", got, tt.high)
+			}
+		})
+	}
+}
+
+func TestMBC1_RAM(t *testing.T) {
+	rom := carttest.Builder{Type: 0x03, ROMSize: 0x01, RAMSize: 0x03}.Build() // 32KiB RAM, 4 banks
+	mmu := newTestMMU(t, rom)
+
+	mmu.Write(0xA000, 0x42)
+	if got := mmu.Read(0xA000); got != 0xFF {
+		t.Errorf("disabled RAM read 0x%X; want 0xFF", got)
+	}
+
+	mmu.Write(0x0000, 0x0A)
+	mmu.Write(0xA000, 0x10)
+	mmu.Write(0x6000, 0x01)
+	mmu.Write(0x4000, 0x02)
+	mmu.Write(0xA000, 0x12)
+
+	if got := mmu.Read(0xA000); got != 0x12 {
+		t.Errorf("RAM bank 2 = 0x%X; want 0x12", got)
+	}
+	mmu.Write(0x6000, 0x00) // mode 0 forces RAM bank 0
+	if got := mmu.Read(0xA000); got != 0x10 {
+		t.Errorf("RAM bank 0 = 0x%X; want 0x10", got)
+	}
+	if got := mmu.PeekBank(2, 0xA000); got != 0x12 {
+		t.Errorf("PeekBank(2, 0xA000) = 0x%X; want 0x12", got)
+	}
+
+	mmu.Write(0x0000, 0x00)
+	if got := mmu.Read(0xA000); got != 0xFF {
+		t.Errorf("RAM still readable after disable, got 0x%X", got)
+	}
+}
+
+func TestMBC1_Multicart(t *testing.T) {
+	rom := carttest.Builder{Type: 0x01, ROMSize: 0x05}.Build() // 1MiB
+	copy(rom[0x10*ROMBankSize+LogoAddr:], Logo[:])
+	markBanks(rom)
+	mmu := newTestMMU(t, rom)
+
+	// BANK2 = 1 selects the second game: bank 0x10 at 0x0000 in mode 1,
+	// and BANK1 bit 4 is not connected.
+	mmu.Write(0x6000, 0x01)
+	mmu.Write(0x4000, 0x01)
+	mmu.Write(0x2000, 0x13)
+
+	if got := mmu.Read(0x0200); got != 0x10 {
+		t.Errorf("bank at 0x0000 = 0x%X; want 0x10", got)
+	}
+	if got := mmu.Read(0x4200); got != 0x13 {
+		t.Errorf("bank at 0x4000 = 0x%X; want 0x13", got)
+	}
+
+	mmu.Write(0x2000, 0x05)
+	if got := mmu.Read(0x4200); got != 0x15 {
+		t.Errorf("bank at 0x4000 = 0x%X; want 0x15", got)
+	}
+}
+
+func TestMBC1_RegularOneMegCart(t *testing.T) {
+	rom := carttest.Builder{Type: 0x01, ROMSize: 0x05}.Build() // 1MiB without a second logo
+	markBanks(rom)
+	mmu := newTestMMU(t, rom)
+
+	mmu.Write(0x4000, 0x01)
+	mmu.Write(0x2000, 0x13)
+	if got := mmu.Read(0x4200); got != 0x33 {
+		t.Errorf("bank at 0x4000 = 0x%X; want 0x33", got)
+	}
+}
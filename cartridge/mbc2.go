@@ -0,0 +1,84 @@
+package cartridge
+
+// MBC2
+// -----------------------------
+// Registers live at 0x0000-0x3FFF and address bit 8 picks which one:
+//   bit 8 clear  RAM enable: 0x?A enables, anything else disables
+//   bit 8 set    ROM bank: low 4 bits, 0 is treated as 1 (max 256KiB)
+//
+// The RAM is built into the MBC: 512 cells of 4 bits. Only address bits
+// 0-8 are decoded, so the 512 cells echo across all of 0xA000-0xBFFF, and
+// the upper nibble is not driven and reads back as 1s.
+// Source: https://gbdev.io/pandocs/MBC2.html
+// -----------------------------
+
+const mbc2RAMSize = 512
+
+type mbc2 struct {
+	banks
+	ramEnabled bool
+	romBank    byte
+}
+
+func newMBC2(rom []byte, h *Header) *mbc2 {
+	return &mbc2{banks: newBanks(rom, mbc2RAMSize), romBank: 1}
+}
+
+func (c *mbc2) Read(addr uint16) byte {
+	if addr >= 0xA000 && addr <= 0xBFFF && !c.ramEnabled {
+		return 0xFF
+	}
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *mbc2) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x3FFF:
+		if addr&0x0100 == 0 {
+			c.ramEnabled = data&0x0F == 0x0A
+			return
+		}
+		c.romBank = data & 0x0F
+		if c.romBank == 0 {
+			c.romBank = 1
+		}
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if c.ramEnabled {
+			c.ram[addr&(mbc2RAMSize-1)] = data & 0x0F
+		}
+	}
+}
+
+func (c *mbc2) Bank(addr uint16) int {
+	if addr >= 0x4000 && addr <= 0x7FFF {
+		return int(c.romBank)
+	}
+	return 0
+}
+
+func (c *mbc2) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return 0xF0 | c.ram[addr&(mbc2RAMSize-1)]
+	}
+	return 0xFF
+}
+
+func (c *mbc2) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.ram[addr&(mbc2RAMSize-1)] = data & 0x0F
+	}
+}
+
+// ReadPage only maps ROM, the nibble RAM needs its upper bits forced on.
+func (c *mbc2) ReadPage(addr uint16) []byte {
+	if addr <= 0x7FFF {
+		return c.romPage(c.Bank(addr), addr)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+func TestMBC2_RegisterSelectByBit8(t *testing.T) {
+	rom := carttest.Builder{Type: 0x06, ROMSize: 0x03}.Build() // 256KiB
+	markBanks(rom)
+	mmu := newTestMMU(t, rom)
+
+	mmu.Write(0x2100, 0x05)
+	if got := mmu.Read(0x4200); got != 0x05 {
+		t.Errorf("bank at 0x4000 = 0x%X; want 0x05", got)
+	}
+
+	// Bit 8 clear: RAM enable, the ROM bank must not change.
+	mmu.Write(0x2000, 0x0A)
+	if got := mmu.Read(0x4200); got != 0x05 {
+		t.Errorf("RAM enable write changed the ROM bank to 0x%X", got)
+	}
+	mmu.Write(0xA000, 0x03)
+	if got := mmu.Read(0xA000); got != 0xF3 {
+		t.Errorf("RAM enable at 0x2000 had no effect, Read(0xA000) = 0x%X", got)
+	}
+
+	mmu.Write(0x0100, 0x00)
+	if got := mmu.Read(0x4200); got != 0x01 {
+		t.Errorf("bank 0 mapped 0x%X; want 0x01", got)
+	}
+	mmu.Write(0x3FFF, 0x1F) // only 4 bits
+	if got := mmu.Read(0x4200); got != 0x0F {
+		t.Errorf("bank at 0x4000 = 0x%X; want 0x0F", got)
+	}
+}
+
+func TestMBC2_NibbleRAM(t *testing.T) {
+	mmu := newTestMMU(t, carttest.Builder{Type: 0x06}.Build())
+
+	mmu.Write(0xA000, 0x05)
+	if got := mmu.Read(0xA000); got != 0xFF {
+		t.Errorf("disabled RAM read 0x%X; want 0xFF", got)
+	}
+
+	mmu.Write(0x0000, 0x0A)
+	mmu.Write(0xA010, 0xA5)
+
+	tests := []struct {
+		name string
+		addr uint16
+	}{
+		{"Written cell", 0xA010},
+		{"Echo at 0xA210", 0xA210},
+		{"Echo at 0xBE10", 0xBE10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mmu.Read(tt.addr); got != 0xF5 {
+				t.Errorf("Read(0x%X) = 0x%X; want 0xF5", tt.addr, got)
+			}
+		})
+	}
+}
+
+func TestMBC2_SearchNoEchoes(t *testing.T) {
+	mmu := newTestMMU(t, carttest.Builder{Type: 0x06, ROMSize: 0x01}.Build())
+	mmu.PokeBank(0, 0xA010, 0x07)
+
+	search := memory.NewSearch(mmu, memory.Size8, memory.Unsigned)
+	search.Filter(search.Snapshot(), memory.Equal, 0xF7)
+	got := search.Candidates()
+	if len(got) != 1 || got[0].Bank != 0 || got[0].Addr != 0xA010 {
+		t.Errorf("Candidates() = %+v; want only 00:A010, not its echoes", got)
+	}
+}
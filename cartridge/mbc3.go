@@ -0,0 +1,134 @@
+package cartridge
+
+// MBC3
+// -----------------------------
+// Registers (write-only, selected by address):
+//   0x0000-0x1FFF  RAM and timer enable: 0x?A enables, anything else disables
+//   0x2000-0x3FFF  ROM bank: 7 bits, 0 is treated as 1
+//   0x4000-0x5FFF  0x00-0x03 selects a RAM bank, 0x08-0x0C an RTC register
+//   0x6000-0x7FFF  latch clock data: write 0x00 then 0x01
+//
+// MBC30 (Pocket Monsters Crystal JP) is the same chip with an 8th ROM bank
+// bit and 8 RAM banks; it is picked when the ROM is larger than 2MiB or the
+// RAM larger than 32KiB.
+// Source: https://gbdev.io/pandocs/MBC3.html
+// -----------------------------
+
+type mbc3 struct {
+	banks
+	ramEnabled bool
+	romBank    byte
+	// ramSelect is the raw 0x4000 register: a RAM bank or an RTC register.
+	ramSelect byte
+
+	romMask byte
+	ramMask byte
+
+	// rtc is nil on boards without TIMER.
+	rtc *rtc
+}
+
+func newMBC3(rom []byte, h *Header, clock Clock) *mbc3 {
+	c := &mbc3{banks: newBanks(rom, h.RAMSize), romBank: 1, romMask: 0x7F, ramMask: 0x03}
+	if len(rom) > 128*ROMBankSize || h.RAMSize > 4*RAMBankSize {
+		c.romMask, c.ramMask = 0xFF, 0x07
+	}
+	if h.Type.Timer {
+		c.rtc = newRTC(clock)
+	}
+	return c
+}
+
+// rtcSelected reports whether 0xA000-0xBFFF currently shows an RTC register.
+func (c *mbc3) rtcSelected() bool {
+	return c.ramSelect >= rtcSeconds && c.ramSelect <= rtcDayHigh
+}
+
+func (c *mbc3) Read(addr uint16) byte {
+	if addr >= 0xA000 && addr <= 0xBFFF {
+		if !c.ramEnabled {
+			return 0xFF
+		}
+		if c.rtcSelected() {
+			if c.rtc == nil {
+				return 0xFF
+			}
+			return c.rtc.read(c.ramSelect)
+		}
+	}
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *mbc3) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.ramEnabled = data&0x0F == 0x0A
+	case addr <= 0x3FFF:
+		c.romBank = data & c.romMask
+		if c.romBank == 0 {
+			c.romBank = 1
+		}
+	case addr <= 0x5FFF:
+		c.ramSelect = data & 0x0F
+	case addr <= 0x7FFF:
+		if c.rtc != nil {
+			c.rtc.writeLatch(data)
+		}
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if !c.ramEnabled {
+			return
+		}
+		if c.rtcSelected() {
+			if c.rtc != nil {
+				c.rtc.write(c.ramSelect, data)
+			}
+			return
+		}
+		c.setRAMByte(c.ramBank(), addr, data)
+	}
+}
+
+func (c *mbc3) ramBank() int {
+	return int(c.ramSelect & c.ramMask)
+}
+
+func (c *mbc3) Bank(addr uint16) int {
+	switch {
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return int(c.romBank)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramBank()
+	}
+	return 0
+}
+
+// PeekBank always addresses RAM banks; the RTC registers are reachable
+// through the bus with no side effects anyway.
+func (c *mbc3) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *mbc3) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+func (c *mbc3) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && c.ramEnabled && !c.rtcSelected():
+		return c.ramPage(c.ramBank(), addr)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package cartridge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+// fakeClock is a Clock tests move by hand.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newRTCTestMMU(t *testing.T) (*memory.MMU, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cart, err := NewWithOptions(carttest.Builder{Type: 0x10, ROMSize: 0x02, RAMSize: 0x03}.Build(), Options{Clock: clock})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error: %v", err)
+	}
+	mmu := memory.NewMMU(cart)
+	mmu.Write(0x0000, 0x0A)
+	return mmu, clock
+}
+
+// latch runs the 0x00 -> 0x01 sequence.
+func latch(mmu *memory.MMU) {
+	mmu.Write(0x6000, 0x00)
+	mmu.Write(0x6000, 0x01)
+}
+
+func readRTC(mmu *memory.MMU, reg byte) byte {
+	mmu.Write(0x4000, reg)
+	return mmu.Read(0xA000)
+}
+
+func TestMBC3_ROMAndRAMBanking(t *testing.T) {
+	rom := carttest.Builder{Type: 0x13, ROMSize: 0x06, RAMSize: 0x03}.Build() // 2MiB, 32KiB RAM
+	markBanks(rom)
+	mmu := newTestMMU(t, rom)
+
+	mmu.Write(0x2000, 0x00)
+	if got := mmu.Read(0x4200); got != 0x01 {
+		t.Errorf("bank 0 mapped 0x%X; want 0x01", got)
+	}
+	mmu.Write(0x2000, 0x7F)
+	if got := mmu.Read(0x4200); got != 0x7F {
+		t.Errorf("bank at 0x4000 = 0x%X; want 0x7F", got)
+	}
+
+	mmu.Write(0x0000, 0x0A)
+	for bank := byte(0); bank < 4; bank++ {
+		mmu.Write(0x4000, bank)
+		mmu.Write(0xA100, 0xB0|bank)
+	}
+	mmu.Write(0x4000, 0x02)
+	if got := mmu.Read(0xA100); got != 0xB2 {
+		t.Errorf("RAM bank 2 = 0x%X; want 0xB2", got)
+	}
+}
+
+func TestMBC3_RTCLatch(t *testing.T) {
+	mmu, clock := newRTCTestMMU(t)
+
+	clock.advance(1*time.Hour + 2*time.Minute + 3*time.Second)
+	if got := readRTC(mmu, rtcSeconds); got != 0 {
+		t.Errorf("seconds before latch = %d; want 0", got)
+	}
+
+	latch(mmu)
+	if s, m, h := readRTC(mmu, rtcSeconds), readRTC(mmu, rtcMinutes), readRTC(mmu, rtcHours); s != 3 || m != 2 || h != 1 {
+		t.Errorf("latched %02d:%02d:%02d; want 01:02:03", h, m, s)
+	}
+
+	// The latch holds while time moves on.
+	clock.advance(10 * time.Second)
+	if got := readRTC(mmu, rtcSeconds); got != 3 {
+		t.Errorf("latched seconds changed to %d", got)
+	}
+
+	// 0x01 alone doesn't latch.
+	mmu.Write(0x6000, 0x01)
+	if got := readRTC(mmu, rtcSeconds); got != 3 {
+		t.Errorf("latched without the 0x00 write, seconds = %d", got)
+	}
+	latch(mmu)
+	if got := readRTC(mmu, rtcSeconds); got != 13 {
+		t.Errorf("seconds after second latch = %d; want 13", got)
+	}
+}
+
+func TestMBC3_RTCHalt(t *testing.T) {
+	mmu, clock := newRTCTestMMU(t)
+
+	mmu.Write(0x4000, rtcDayHigh)
+	mmu.Write(0xA000, rtcHalt)
+	clock.advance(time.Hour)
+	latch(mmu)
+	if got := readRTC(mmu, rtcMinutes); got != 0 {
+		t.Errorf("halted clock counted to %d minutes", got)
+	}
+
+	mmu.Write(0x4000, rtcDayHigh)
+	mmu.Write(0xA000, 0x00)
+	clock.advance(5 * time.Second)
+	latch(mmu)
+	if got := readRTC(mmu, rtcSeconds); got != 5 {
+		t.Errorf("seconds after resume = %d; want 5", got)
+	}
+}
+
+func TestMBC3_RTCDayCarry(t *testing.T) {
+	mmu, clock := newRTCTestMMU(t)
+
+	mmu.Write(0x4000, rtcDayLow)
+	mmu.Write(0xA000, 0xFF)
+	mmu.Write(0x4000, rtcDayHigh)
+	mmu.Write(0xA000, rtcDayBit8) // day 511
+
+	clock.advance(24 * time.Hour)
+	latch(mmu)
+	dh := readRTC(mmu, rtcDayHigh)
+	if dl := readRTC(mmu, rtcDayLow); dl != 0 || dh&rtcDayBit8 != 0 {
+		t.Errorf("day counter = %d; want 0 after overflow", int(dh&1)<<8|int(dl))
+	}
+	if dh&rtcCarry == 0 {
+		t.Errorf("day carry not set after overflow, DH = 0x%X", dh)
+	}
+
+	// Carry is sticky until the game clears it.
+	clock.advance(24 * time.Hour)
+	latch(mmu)
+	if readRTC(mmu, rtcDayHigh)&rtcCarry == 0 {
+		t.Errorf("day carry cleared by itself")
+	}
+}
+
+func TestMBC3_RTCOutOfRangeWraps(t *testing.T) {
+	regs := rtcRegisters{Seconds: 62, Minutes: 59}
+	regs.advance(3)
+	if regs.Seconds != 1 || regs.Minutes != 59 {
+		t.Errorf("62s + 3 = %dm%ds; want 59m1s (no carry on wrap)", regs.Minutes, regs.Seconds)
+	}
+}
+
+func TestCycleClock(t *testing.T) {
+	start := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewCycleClock(start)
+	clock.Tick(CPUFrequency * 3)
+	clock.Tick(CPUFrequency / 2)
+	if got := clock.Now().Sub(start); got != 3500*time.Millisecond {
+		t.Errorf("elapsed = %v; want 3.5s", got)
+	}
+}
@@ -0,0 +1,116 @@
+package cartridge
+
+// MBC5
+// -----------------------------
+// Registers (write-only, selected by address):
+//   0x0000-0x1FFF  RAM enable: exactly 0x0A enables, anything else disables
+//   0x2000-0x2FFF  ROM bank, low 8 bits
+//   0x3000-0x3FFF  ROM bank, bit 8 (up to 512 banks / 8MiB)
+//   0x4000-0x5FFF  RAM bank 0x00-0x0F (up to 128KiB)
+//
+// Unlike MBC1/MBC3, bank 0 can be mapped at 0x4000-0x7FFF.
+// On rumble boards bit 3 of the RAM bank register drives the motor instead
+// of a RAM address line, so only 8 RAM banks are reachable there.
+// Source: https://gbdev.io/pandocs/MBC5.html
+// -----------------------------
+
+const mbc5RumbleBit = 1 << 3
+
+type mbc5 struct {
+	banks
+	ramEnabled bool
+	romBank    uint16
+	ramBank    byte
+
+	rumble   bool
+	motorOn  bool
+	onRumble func(on bool)
+}
+
+func newMBC5(rom []byte, h *Header, onRumble func(on bool)) *mbc5 {
+	return &mbc5{
+		banks:    newBanks(rom, h.RAMSize),
+		romBank:  1,
+		rumble:   h.Type.Rumble,
+		onRumble: onRumble,
+	}
+}
+
+func (c *mbc5) Read(addr uint16) byte {
+	if addr >= 0xA000 && addr <= 0xBFFF && !c.ramEnabled {
+		return 0xFF
+	}
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *mbc5) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.ramEnabled = data == 0x0A
+	case addr <= 0x2FFF:
+		c.romBank = c.romBank&0x100 | uint16(data)
+	case addr <= 0x3FFF:
+		c.romBank = c.romBank&0xFF | uint16(data&0x01)<<8
+	case addr <= 0x5FFF:
+		c.ramBank = data & 0x0F
+		if c.rumble {
+			c.setMotor(data&mbc5RumbleBit != 0)
+			c.ramBank &^= mbc5RumbleBit
+		}
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if c.ramEnabled {
+			c.setRAMByte(int(c.ramBank), addr, data)
+		}
+	}
+}
+
+// setMotor reports rumble state changes to the frontend. Games pulse the
+// motor bit every frame to vary the strength, so only edges are reported.
+func (c *mbc5) setMotor(on bool) {
+	if on == c.motorOn {
+		return
+	}
+	c.motorOn = on
+	if c.onRumble != nil {
+		c.onRumble(on)
+	}
+}
+
+func (c *mbc5) Bank(addr uint16) int {
+	switch {
+	case addr >= 0x4000 && addr <= 0x7FFF:
+		return int(c.romBank)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return int(c.ramBank)
+	}
+	return 0
+}
+
+func (c *mbc5) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *mbc5) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+func (c *mbc5) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && c.ramEnabled:
+		return c.ramPage(int(c.ramBank), addr)
+	}
+	return nil
+}
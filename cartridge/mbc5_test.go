@@ -0,0 +1,79 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+func TestMBC5_ROMBanking(t *testing.T) {
+	rom := carttest.Builder{Type: 0x19, ROMSize: 0x08}.Build() // 8MiB, 512 banks
+	markBanks(rom)
+	rom[0x1FF*ROMBankSize+0x0201] = 0xEE // markBanks only stores the low byte
+	carttest.Fix(rom)
+	mmu := newTestMMU(t, rom)
+
+	mmu.Write(0x2000, 0x00)
+	if got := mmu.Read(0x4200); got != 0x00 {
+		t.Errorf("bank 0 at 0x4000 = 0x%X; want 0x00", got)
+	}
+
+	mmu.Write(0x2000, 0xFF)
+	mmu.Write(0x3000, 0x01)
+	if got := mmu.Read(0x4201); got != 0xEE {
+		t.Errorf("bank 0x1FF not mapped, Read(0x4201) = 0x%X", got)
+	}
+	if got := mmu.Bank(0x4000); got != 0x1FF {
+		t.Errorf("Bank(0x4000) = 0x%X; want 0x1FF", got)
+	}
+
+	mmu.Write(0x3000, 0x00)
+	if got := mmu.Read(0x4200); got != 0xFF {
+		t.Errorf("bank at 0x4000 = 0x%X; want 0xFF", got)
+	}
+}
+
+func TestMBC5_RAMBanking(t *testing.T) {
+	mmu := newTestMMU(t, carttest.Builder{Type: 0x1B, ROMSize: 0x01, RAMSize: 0x04}.Build()) // 128KiB RAM
+
+	mmu.Write(0x0000, 0x0A)
+	for bank := byte(0); bank < 16; bank++ {
+		mmu.Write(0x4000, bank)
+		mmu.Write(0xB000, bank)
+	}
+	mmu.Write(0x4000, 0x0F)
+	if got := mmu.Read(0xB000); got != 0x0F {
+		t.Errorf("RAM bank 15 = 0x%X; want 0x0F", got)
+	}
+
+	mmu.Write(0x0000, 0x1A) // MBC5 decodes all 8 bits
+	if got := mmu.Read(0xB000); got != 0xFF {
+		t.Errorf("0x1A enabled RAM, Read(0xB000) = 0x%X", got)
+	}
+}
+
+func TestMBC5_Rumble(t *testing.T) {
+	var events []bool
+	cart, err := NewWithOptions(carttest.Builder{Type: 0x1E, ROMSize: 0x01, RAMSize: 0x03}.Build(), Options{
+		OnRumble: func(on bool) { events = append(events, on) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mmu := memory.NewMMU(cart)
+	mmu.Write(0x0000, 0x0A)
+
+	mmu.Write(0x4000, 0x01)
+	mmu.Write(0xA000, 0x11)
+	mmu.Write(0x4000, 0x09) // motor on, still RAM bank 1
+	mmu.Write(0x4000, 0x09)
+	if got := mmu.Read(0xA000); got != 0x11 {
+		t.Errorf("rumble bit changed the RAM bank, Read(0xA000) = 0x%X", got)
+	}
+	mmu.Write(0x4000, 0x01)
+
+	if len(events) != 2 || !events[0] || events[1] {
+		t.Errorf("rumble events = %v; want [true false]", events)
+	}
+}
@@ -0,0 +1,170 @@
+package cartridge
+
+// MBC7
+// -----------------------------
+// Registers (write-only, selected by address):
+//   0x0000-0x1FFF  RAM enable 1: 0x0A
+//   0x2000-0x3FFF  ROM bank
+//   0x4000-0x5FFF  RAM enable 2: 0x40
+//
+// With both enables set, 0xA000-0xAFFF is a register window decoded by
+// address bits 4-7 (0xAx?0 style, mirrored):
+//   Ax0x  write 0x55: erase the accelerometer latch (both axes = 0x8000)
+//   Ax1x  write 0xAA: latch the accelerometer (only after an erase)
+//   Ax2x  X low   Ax3x  X high   Ax4x  Y low   Ax5x  Y high
+//   Ax6x  reads 0x00             Ax7x  reads 0xFF
+//   Ax8x  93LC56 EEPROM pins, see eeprom.go
+// Everything else in 0xA000-0xBFFF reads 0xFF.
+//
+// The accelerometer reads about 0x81D0 when level and moves about 0x70
+// per g.
+// Source: https://gbdev.io/pandocs/MBC7.html
+// -----------------------------
+
+const (
+	accelCenter   = 0x81D0
+	accelPerG     = 0x70
+	accelErased   = 0x8000
+	accelEraseCmd = 0x55
+	accelLatchCmd = 0xAA
+)
+
+type mbc7 struct {
+	banks
+	ramEnable1 bool
+	ramEnable2 bool
+	romBank    byte
+
+	tilt        TiltSource
+	accelX      uint16
+	accelY      uint16
+	latchErased bool
+	eeprom      *eeprom
+}
+
+func newMBC7(rom []byte, h *Header, tilt TiltSource) *mbc7 {
+	c := &mbc7{
+		banks:   newBanks(rom, eepromSize),
+		romBank: 1,
+		tilt:    tilt,
+		accelX:  accelErased,
+		accelY:  accelErased,
+	}
+	// blank EEPROMs read as all 1s
+	for i := range c.ram {
+		c.ram[i] = 0xFF
+	}
+	c.eeprom = newEEPROM(c.ram)
+	return c
+}
+
+func (c *mbc7) registersEnabled() bool {
+	return c.ramEnable1 && c.ramEnable2
+}
+
+func (c *mbc7) Read(addr uint16) byte {
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *mbc7) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.ramEnable1 = data == 0x0A
+	case addr <= 0x3FFF:
+		c.romBank = data
+	case addr <= 0x5FFF:
+		c.ramEnable2 = data == 0x40
+	case addr >= 0xA000 && addr <= 0xAFFF:
+		if c.registersEnabled() {
+			c.writeRegister(byte(addr>>4)&0x0F, data)
+		}
+	}
+}
+
+func (c *mbc7) writeRegister(reg byte, data byte) {
+	switch reg {
+	case 0x0:
+		if data == accelEraseCmd {
+			c.accelX, c.accelY = accelErased, accelErased
+			c.latchErased = true
+		}
+	case 0x1:
+		if data == accelLatchCmd && c.latchErased {
+			c.latchTilt()
+			c.latchErased = false
+		}
+	case 0x8:
+		c.eeprom.write(data)
+	}
+}
+
+// latchTilt samples the tilt source into the accelerometer registers.
+func (c *mbc7) latchTilt() {
+	var x, y float64
+	if c.tilt != nil {
+		x, y = c.tilt.Tilt()
+	}
+	c.accelX = accelValue(x)
+	c.accelY = accelValue(y)
+}
+
+// accelValue converts g to a sensor reading, clamped to +-2g.
+func accelValue(g float64) uint16 {
+	if g > 2 {
+		g = 2
+	} else if g < -2 {
+		g = -2
+	}
+	return uint16(accelCenter + int(g*accelPerG))
+}
+
+func (c *mbc7) readRegister(reg byte) byte {
+	switch reg {
+	case 0x2:
+		return byte(c.accelX)
+	case 0x3:
+		return byte(c.accelX >> 8)
+	case 0x4:
+		return byte(c.accelY)
+	case 0x5:
+		return byte(c.accelY >> 8)
+	case 0x6:
+		return 0x00
+	case 0x8:
+		return c.eeprom.read()
+	}
+	return 0xFF
+}
+
+func (c *mbc7) Bank(addr uint16) int {
+	if addr >= 0x4000 && addr <= 0x7FFF {
+		return int(c.romBank)
+	}
+	return 0
+}
+
+// PeekBank reads ROM, or the register window as the CPU would see it;
+// reading registers has no side effects.
+func (c *mbc7) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xAFFF && c.registersEnabled():
+		return c.readRegister(byte(addr>>4) & 0x0F)
+	}
+	return 0xFF
+}
+
+// PokeBank only patches ROM, the EEPROM is only reachable through its pins.
+func (c *mbc7) PokeBank(bank int, addr uint16, data byte) {
+	if addr <= 0x7FFF {
+		c.rom[c.romOffset(bank, addr)] = data
+	}
+}
+
+func (c *mbc7) ReadPage(addr uint16) []byte {
+	if addr <= 0x7FFF {
+		return c.romPage(c.Bank(addr), addr)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+const eepromPins = 0xA080
+
+func newMBC7TestMMU(t *testing.T, tilt TiltSource) (*memory.MMU, *Cart) {
+	t.Helper()
+	cart, err := NewWithOptions(carttest.Builder{Type: 0x22, ROMSize: 0x05}.Build(), Options{Tilt: tilt})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error: %v", err)
+	}
+	mmu := memory.NewMMU(cart)
+	mmu.Write(0x0000, 0x0A)
+	mmu.Write(0x4000, 0x40)
+	return mmu, cart
+}
+
+// sendBits clocks bits (MSB first) into the EEPROM with CS held high.
+func sendBits(mmu *memory.MMU, value uint32, count int) {
+	for i := count - 1; i >= 0; i-- {
+		di := byte(value>>uint(i)&1) << 1
+		mmu.Write(eepromPins, 0x80|di)
+		mmu.Write(eepromPins, 0x80|0x40|di)
+	}
+}
+
+// receiveWord clocks 16 bits out of the EEPROM.
+func receiveWord(mmu *memory.MMU) uint16 {
+	var word uint16
+	for i := 0; i < 16; i++ {
+		mmu.Write(eepromPins, 0x80)
+		mmu.Write(eepromPins, 0x80|0x40)
+		word = word<<1 | uint16(mmu.Read(eepromPins)&0x01)
+	}
+	return word
+}
+
+func deselect(mmu *memory.MMU) {
+	mmu.Write(eepromPins, 0x00)
+}
+
+func TestMBC7_EEPROMWriteRead(t *testing.T) {
+	mmu, cart := newMBC7TestMMU(t, nil)
+
+	sendBits(mmu, 0b1_00_11000000, 11) // EWEN
+	deselect(mmu)
+	sendBits(mmu, 0b1_01_00000101, 11) // WRITE word 5
+	sendBits(mmu, 0xBEEF, 16)
+	deselect(mmu)
+
+	sendBits(mmu, 0b1_10_00000101, 11) // READ word 5
+	if got := mmu.Read(eepromPins) & 0x01; got != 0 {
+		t.Errorf("dummy bit = %d; want 0", got)
+	}
+	if got := receiveWord(mmu); got != 0xBEEF {
+		t.Errorf("READ word 5 = 0x%X; want 0xBEEF", got)
+	}
+	if got := receiveWord(mmu); got != 0xFFFF {
+		t.Errorf("sequential READ word 6 = 0x%X; want blank 0xFFFF", got)
+	}
+	deselect(mmu)
+
+	if ram := cart.RAM(); ram[10] != 0xEF || ram[11] != 0xBE {
+		t.Errorf("EEPROM image bytes 10-11 = %X %X; want EF BE", ram[10], ram[11])
+	}
+}
+
+func TestMBC7_EEPROMWriteProtected(t *testing.T) {
+	mmu, cart := newMBC7TestMMU(t, nil)
+
+	sendBits(mmu, 0b1_01_00000000, 11) // WRITE without EWEN
+	sendBits(mmu, 0x1234, 16)
+	deselect(mmu)
+
+	if ram := cart.RAM(); ram[0] != 0xFF || ram[1] != 0xFF {
+		t.Errorf("write-protected EEPROM changed to %X %X", ram[0], ram[1])
+	}
+}
+
+func TestMBC7_EEPROMPersistence(t *testing.T) {
+	mmu, cart := newMBC7TestMMU(t, nil)
+	save := make([]byte, eepromSize)
+	save[6], save[7] = 0x34, 0x12
+	if err := cart.LoadRAM(save); err != nil {
+		t.Fatal(err)
+	}
+
+	sendBits(mmu, 0b1_10_00000011, 11) // READ word 3
+	if got := receiveWord(mmu); got != 0x1234 {
+		t.Errorf("READ word 3 = 0x%X; want 0x1234 from the loaded save", got)
+	}
+}
+
+func TestMBC7_Accelerometer(t *testing.T) {
+	tilt := &FixedTilt{}
+	mmu, _ := newMBC7TestMMU(t, tilt)
+	readAxis := func(lo uint16) uint16 {
+		return uint16(mmu.Read(lo)) | uint16(mmu.Read(lo+0x10))<<8
+	}
+
+	tilt.Set(1, -0.5)
+	mmu.Write(0xA000, 0x55)
+	if x := readAxis(0xA020); x != 0x8000 {
+		t.Errorf("erased X = 0x%X; want 0x8000", x)
+	}
+	mmu.Write(0xA010, 0xAA)
+	if x, y := readAxis(0xA020), readAxis(0xA040); x != 0x81D0+0x70 || y != 0x81D0-0x38 {
+		t.Errorf("latched X, Y = 0x%X, 0x%X; want 0x%X, 0x%X", x, y, 0x81D0+0x70, 0x81D0-0x38)
+	}
+
+	// Without a new erase, latching again keeps the old sample.
+	tilt.Set(0, 0)
+	mmu.Write(0xA010, 0xAA)
+	if x := readAxis(0xA020); x != 0x81D0+0x70 {
+		t.Errorf("latch without erase resampled X = 0x%X", x)
+	}
+
+	script := TiltFunc(func() (float64, float64) { return 0, 0 })
+	mmu, _ = newMBC7TestMMU(t, script)
+	mmu.Write(0xA000, 0x55)
+	mmu.Write(0xA010, 0xAA)
+	if x := readAxis(0xA020); x != 0x81D0 {
+		t.Errorf("level X = 0x%X; want 0x81D0", x)
+	}
+}
+
+func TestMBC7_RegistersNeedBothEnables(t *testing.T) {
+	mmu, _ := newMBC7TestMMU(t, nil)
+	mmu.Write(0x4000, 0x00)
+	if got := mmu.Read(0xA060); got != 0xFF {
+		t.Errorf("register window readable with enable 2 off, got 0x%X", got)
+	}
+}
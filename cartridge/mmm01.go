@@ -0,0 +1,171 @@
+package cartridge
+
+// MMM01
+// -----------------------------
+// Multi-game mapper. At power on it is "unmapped": the last 32KiB of the
+// ROM (the menu, which also holds the real header) appears at
+// 0x0000-0x7FFF. The menu programs the outer bank bits, then sets the map
+// enable bit, after which the cart behaves like an MBC1 confined to one
+// game's slice of the ROM.
+//   0x0000-0x1FFF  bits 0-3 RAM enable (0xA), bits 4-5 RAM bank mask,
+//                  bit 6 map enable (locks the outer bits)
+//   0x2000-0x3FFF  bits 0-4 ROM bank low, bits 5-6 ROM bank mid
+//   0x4000-0x5FFF  bits 0-1 RAM bank low, bits 2-3 RAM bank high,
+//                  bits 4-5 ROM bank high
+//   0x6000-0x7FFF  bits 2-5 ROM bank mask
+// A set mask bit freezes the matching bank bit (ROM bank low bits 1-4, RAM
+// bank low bits 0-1) at the value it had when the mapping was locked.
+//
+// The MBC1 mode bit and multiplexing are not emulated; none of the
+// released multicarts rely on them.
+// Source: https://gbdev.io/pandocs/MMM01.html
+// -----------------------------
+
+const mmm01MapEnable = 0x40
+
+type mmm01 struct {
+	banks
+	mapped     bool
+	ramEnabled bool
+
+	romLow, romMid, romHigh byte
+	romMask                 byte // locked bits of romLow
+	ramLow, ramHigh         byte
+	ramMask                 byte // locked bits of ramLow
+}
+
+func newMMM01(rom []byte, h *Header) *mmm01 {
+	return &mmm01{banks: newBanks(rom, h.RAMSize)}
+}
+
+// mmm01Header returns the header of an MMM01 multicart, which lives in the
+// menu at the end of the ROM, or nil if rom isn't one.
+func mmm01Header(rom []byte) *Header {
+	base := len(rom) - minimumROMSize
+	if base <= 0 {
+		return nil
+	}
+	h, err := parseHeaderAt(rom, base)
+	if err != nil || h.Type.MBC != MMM01 {
+		return nil
+	}
+	return h
+}
+
+// romBank returns the full ROM bank number from the three registers.
+func (c *mmm01) romBank() int {
+	return int(c.romHigh)<<7 | int(c.romMid)<<5 | int(c.romLow)
+}
+
+// lowBank returns the ROM bank mapped at 0x0000-0x3FFF: the game's first
+// bank, i.e. the full bank number with the writable bits cleared.
+func (c *mmm01) lowBank() int {
+	if !c.mapped {
+		return c.romBanks() - 2
+	}
+	return c.romBank() &^ int(0x1F&^c.romMask)
+}
+
+// highBank returns the ROM bank mapped at 0x4000-0x7FFF, with MBC1's
+// 0 -> 1 fix applied to the writable bits.
+func (c *mmm01) highBank() int {
+	if !c.mapped {
+		return c.romBanks() - 1
+	}
+	bank := c.romBank()
+	if c.romLow&^c.romMask == 0 {
+		bank |= 1
+	}
+	return bank
+}
+
+func (c *mmm01) ramBank() int {
+	return int(c.ramHigh)<<2 | int(c.ramLow)
+}
+
+// setLocked stores value into reg, keeping the bits in locked once the
+// mapping is enabled.
+func (c *mmm01) setLocked(reg *byte, value, locked byte) {
+	if c.mapped {
+		value = *reg&locked | value&^locked
+	}
+	*reg = value
+}
+
+func (c *mmm01) Read(addr uint16) byte {
+	if addr >= 0xA000 && addr <= 0xBFFF && !c.ramEnabled {
+		return 0xFF
+	}
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *mmm01) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x1FFF:
+		c.ramEnabled = data&0x0F == 0x0A
+		if !c.mapped {
+			c.ramMask = data >> 4 & 0x03
+			c.mapped = data&mmm01MapEnable != 0
+		}
+	case addr <= 0x3FFF:
+		c.setLocked(&c.romLow, data&0x1F, c.romMask)
+		if !c.mapped {
+			c.romMid = data >> 5 & 0x03
+		}
+	case addr <= 0x5FFF:
+		c.setLocked(&c.ramLow, data&0x03, c.ramMask)
+		if !c.mapped {
+			c.ramHigh = data >> 2 & 0x03
+			c.romHigh = data >> 4 & 0x03
+		}
+	case addr <= 0x7FFF:
+		if !c.mapped {
+			c.romMask = data >> 1 & 0x1E
+		}
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		if c.ramEnabled {
+			c.setRAMByte(c.ramBank(), addr, data)
+		}
+	}
+}
+
+func (c *mmm01) Bank(addr uint16) int {
+	switch {
+	case addr <= 0x3FFF:
+		return c.lowBank()
+	case addr <= 0x7FFF:
+		return c.highBank()
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramBank()
+	}
+	return 0
+}
+
+func (c *mmm01) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *mmm01) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+func (c *mmm01) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF && c.ramEnabled:
+		return c.ramPage(c.ramBank(), addr)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+// makeMMM01 builds a 256KiB multicart: an MBC1 game header in bank 0 and
+// the MMM01 menu header in the last 32KiB.
+func makeMMM01() []byte {
+	rom := carttest.Builder{Type: 0x0D, ROMSize: 0x03, RAMSize: 0x03}.Build()
+	markBanks(rom)
+	menu := rom[len(rom)-minimumROMSize:]
+	copy(menu, rom[:0x150])
+	menu[HeaderChecksumAddr] = HeaderChecksum(menu)
+
+	rom[TypeAddr] = 0x01
+	rom[ROMSizeAddr] = 0x02
+	rom[RAMSizeAddr] = 0x00
+	rom[HeaderChecksumAddr] = HeaderChecksum(rom)
+	return rom
+}
+
+func TestMMM01_Detection(t *testing.T) {
+	cart, err := New(makeMMM01())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if cart.Header.Type.MBC != MMM01 {
+		t.Errorf("MBC = %s; want MMM01", cart.Header.Type.MBC)
+	}
+	if cart.Header.RAMSize != 32*1024 {
+		t.Errorf("RAMSize = %d; want the menu header's 32KiB", cart.Header.RAMSize)
+	}
+}
+
+func TestMMM01_MenuThenLock(t *testing.T) {
+	mmu := newTestMMU(t, makeMMM01())
+
+	if got := mmu.Read(0x0200); got != 14 {
+		t.Errorf("unmapped 0x0000 bank = %d; want 14", got)
+	}
+	if got := mmu.Read(0x4200); got != 15 {
+		t.Errorf("unmapped 0x4000 bank = %d; want 15", got)
+	}
+
+	// Select the 8-bank game at bank 8 and lock ROM bank bits 3-4.
+	mmu.Write(0x2000, 0x08)
+	mmu.Write(0x6000, 0x30)
+	mmu.Write(0x0000, 0x40)
+
+	tests := []struct {
+		bank1     byte
+		low, high int
+	}{
+		{0x00, 8, 9},
+		{0x03, 8, 11},
+		{0x1F, 8, 15},
+		{0x10, 8, 9}, // locked bits ignore the write
+	}
+	for _, tt := range tests {
+		mmu.Write(0x2000, tt.bank1)
+		if got := int(mmu.Read(0x0200)); got != tt.low {
+			t.Errorf("bank1 0x%02X: 0x0000 bank = %d; want %d", tt.bank1, got, tt.low)
+		}
+		if got := int(mmu.Read(0x4200)); got != tt.high {
+			t.Errorf("bank1 0x%02X: 0x4000 bank = %d; want %d", tt.bank1, got, tt.high)
+		}
+	}
+
+	mmu.Write(0x6000, 0x00) // mask is locked too
+	mmu.Write(0x2000, 0x10)
+	if got := mmu.Read(0x4200); got != 9 {
+		t.Errorf("mask changed after lock, 0x4000 bank = %d; want 9", got)
+	}
+}
+
+func TestMMM01_RAM(t *testing.T) {
+	mmu := newTestMMU(t, makeMMM01())
+	mmu.Write(0x0000, 0x4A)
+
+	mmu.Write(0x4000, 0x02)
+	mmu.Write(0xA000, 0x77)
+	mmu.Write(0x4000, 0x00)
+	if got := mmu.Read(0xA000); got == 0x77 {
+		t.Error("RAM bank switch had no effect")
+	}
+	mmu.Write(0x4000, 0x02)
+	if got := mmu.Read(0xA000); got != 0x77 {
+		t.Errorf("RAM bank 2 = 0x%X; want 0x77", got)
+	}
+}
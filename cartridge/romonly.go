@@ -0,0 +1,59 @@
+package cartridge
+
+// romOnly is a cartridge without a mapper: 32KiB of ROM at 0x0000-0x7FFF and
+// optionally up to 8KiB of RAM at 0xA000-0xBFFF (types 0x08/0x09, never used
+// by a licensed game).
+// Source: https://gbdev.io/pandocs/nombc.html
+type romOnly struct {
+	banks
+}
+
+func newROMOnly(rom []byte, h *Header) *romOnly {
+	return &romOnly{banks: newBanks(rom, h.RAMSize)}
+}
+
+func (c *romOnly) Read(addr uint16) byte {
+	return c.PeekBank(c.Bank(addr), addr)
+}
+
+func (c *romOnly) Write(addr uint16, data byte) {
+	if addr >= 0xA000 && addr <= 0xBFFF {
+		c.setRAMByte(0, addr, data)
+	}
+}
+
+func (c *romOnly) Bank(addr uint16) int {
+	if addr >= 0x4000 && addr <= 0x7FFF {
+		return 1
+	}
+	return 0
+}
+
+func (c *romOnly) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romByte(bank, addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramByte(bank, addr)
+	}
+	return 0xFF
+}
+
+func (c *romOnly) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= 0x7FFF:
+		c.rom[c.romOffset(bank, addr)] = data
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		c.setRAMByte(bank, addr, data)
+	}
+}
+
+func (c *romOnly) ReadPage(addr uint16) []byte {
+	switch {
+	case addr <= 0x7FFF:
+		return c.romPage(c.Bank(addr), addr)
+	case addr >= 0xA000 && addr <= 0xBFFF:
+		return c.ramPage(0, addr)
+	}
+	return nil
+}
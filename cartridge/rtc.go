@@ -0,0 +1,212 @@
+package cartridge
+
+import "time"
+
+// MBC3 Real-Time Clock
+// -----------------------------
+// Five registers, selected by writing 0x08-0x0C to 0x4000-0x5FFF and then
+// accessed at 0xA000-0xBFFF:
+//   0x08 S   seconds 0-59 (6 bits)
+//   0x09 M   minutes 0-59 (6 bits)
+//   0x0A H   hours   0-23 (5 bits)
+//   0x0B DL  lower 8 bits of the day counter
+//   0x0C DH  bit 0 day counter bit 8, bit 6 halt, bit 7 day carry
+//
+// Reads see a latched copy: writing 0x00 then 0x01 to 0x6000-0x7FFF copies
+// the live counters into the latch. The day carry stays set once the
+// 9-bit day counter overflows until the game clears it.
+// Out of range values count up to the register's bit width before wrapping
+// to 0, without carrying into the next register, like the real chip.
+// Source: https://gbdev.io/pandocs/MBC3.html
+// -----------------------------
+
+// RTC register select values
+const (
+	rtcSeconds = 0x08
+	rtcMinutes = 0x09
+	rtcHours   = 0x0A
+	rtcDayLow  = 0x0B
+	rtcDayHigh = 0x0C
+)
+
+// DH bits
+const (
+	rtcDayBit8 = 1 << 0
+	rtcHalt    = 1 << 6
+	rtcCarry   = 1 << 7
+)
+
+// rtcRegisters is one set of counters (live or latched).
+type rtcRegisters struct {
+	Seconds byte
+	Minutes byte
+	Hours   byte
+	Days    uint16 // 9 bits
+	Halt    bool
+	Carry   bool
+}
+
+// rtc is the clock chip on MBC3+TIMER boards.
+type rtc struct {
+	clock   Clock
+	live    rtcRegisters
+	latched rtcRegisters
+
+	// last is the clock time the live counters were last brought up to date.
+	last time.Time
+	// latchArmed is set after a 0x00 write to the latch register.
+	latchArmed bool
+	// written is set when the game sets the clock, so the save is flushed
+	// even if RAM didn't change.
+	written bool
+}
+
+func newRTC(clock Clock) *rtc {
+	if clock == nil {
+		clock = HostClock{}
+	}
+	return &rtc{clock: clock, last: clock.Now()}
+}
+
+// update advances the live counters to the clock's current time. Only whole
+// seconds are consumed so the fraction carries over to the next update.
+func (r *rtc) update() {
+	now := r.clock.Now()
+	if r.live.Halt {
+		r.last = now
+		return
+	}
+	elapsed := now.Sub(r.last)
+	if elapsed < time.Second {
+		if elapsed < 0 {
+			r.last = now // host clock went backwards, don't rewind the game
+		}
+		return
+	}
+	secs := int64(elapsed / time.Second)
+	r.last = r.last.Add(time.Duration(secs) * time.Second)
+	r.live.advance(secs)
+}
+
+// advance moves the counters forward by secs seconds.
+func (regs *rtcRegisters) advance(secs int64) {
+	// Tick one second at a time until every register is back in range,
+	// that takes at most 64 ticks per register.
+	for secs > 0 && !regs.valid() {
+		regs.tick()
+		secs--
+	}
+	if secs == 0 {
+		return
+	}
+	total := int64(regs.Seconds) + int64(regs.Minutes)*60 + int64(regs.Hours)*3600 +
+		int64(regs.Days)*86400 + secs
+	regs.Seconds = byte(total % 60)
+	regs.Minutes = byte(total / 60 % 60)
+	regs.Hours = byte(total / 3600 % 24)
+	days := total / 86400
+	if days > 0x1FF {
+		regs.Carry = true
+	}
+	regs.Days = uint16(days & 0x1FF)
+}
+
+func (regs *rtcRegisters) valid() bool {
+	return regs.Seconds < 60 && regs.Minutes < 60 && regs.Hours < 24
+}
+
+// tick advances by one second with the chip's wrap-around rules.
+func (regs *rtcRegisters) tick() {
+	regs.Seconds = (regs.Seconds + 1) & 0x3F
+	if regs.Seconds != 60 {
+		return
+	}
+	regs.Seconds = 0
+	regs.Minutes = (regs.Minutes + 1) & 0x3F
+	if regs.Minutes != 60 {
+		return
+	}
+	regs.Minutes = 0
+	regs.Hours = (regs.Hours + 1) & 0x1F
+	if regs.Hours != 24 {
+		return
+	}
+	regs.Hours = 0
+	regs.Days++
+	if regs.Days > 0x1FF {
+		regs.Days = 0
+		regs.Carry = true
+	}
+}
+
+// writeLatch handles writes to 0x6000-0x7FFF.
+func (r *rtc) writeLatch(data byte) {
+	if data == 0x01 && r.latchArmed {
+		r.update()
+		r.latched = r.live
+	}
+	r.latchArmed = data == 0x00
+}
+
+// read returns the latched value of register reg.
+func (r *rtc) read(reg byte) byte {
+	return r.latched.get(reg)
+}
+
+// write sets register reg on the live counters (and the latch, so the game
+// reads back what it wrote).
+func (r *rtc) write(reg byte, data byte) {
+	r.update()
+	wasHalted := r.live.Halt
+	r.live.set(reg, data)
+	r.latched.set(reg, data)
+	r.written = true
+	if wasHalted && !r.live.Halt {
+		// restart counting from now, not from when the clock was halted
+		r.last = r.clock.Now()
+	}
+}
+
+func (regs *rtcRegisters) get(reg byte) byte {
+	switch reg {
+	case rtcSeconds:
+		return regs.Seconds
+	case rtcMinutes:
+		return regs.Minutes
+	case rtcHours:
+		return regs.Hours
+	case rtcDayLow:
+		return byte(regs.Days)
+	case rtcDayHigh:
+		// unused bits read back as 1s
+		dh := byte(0x3E)
+		if regs.Days&0x100 != 0 {
+			dh |= rtcDayBit8
+		}
+		if regs.Halt {
+			dh |= rtcHalt
+		}
+		if regs.Carry {
+			dh |= rtcCarry
+		}
+		return dh
+	}
+	return 0xFF
+}
+
+func (regs *rtcRegisters) set(reg byte, data byte) {
+	switch reg {
+	case rtcSeconds:
+		regs.Seconds = data & 0x3F
+	case rtcMinutes:
+		regs.Minutes = data & 0x3F
+	case rtcHours:
+		regs.Hours = data & 0x1F
+	case rtcDayLow:
+		regs.Days = regs.Days&0x100 | uint16(data)
+	case rtcDayHigh:
+		regs.Days = regs.Days&0xFF | uint16(data&rtcDayBit8)<<8
+		regs.Halt = data&rtcHalt != 0
+		regs.Carry = data&rtcCarry != 0
+	}
+}
@@ -0,0 +1,192 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// RTC Save Footer
+// -----------------------------
+// Carts with a clock append its state to the .sav after the RAM. MBC3 uses
+// the layout VBA-M introduced and BGB, mGBA and SameBoy all read and write.
+// Every field is little-endian:
+//   0x00  5 x uint32  live S, M, H, DL, DH
+//   0x14  5 x uint32  latched S, M, H, DL, DH
+//   0x28  uint64      Unix time the registers were saved at
+// Older saves store the timestamp as a uint32, making the footer 44 bytes
+// instead of 48; both are read, 48 is written.
+//
+// HuC3 carts don't use it: the HuC3 only counts minutes and days, and
+// squeezing those into the MBC3 registers gives a file no other emulator
+// reads as HuC3. They get the 17-byte footer SameBoy writes for HuC3
+// instead, so saves move between the two. Little-endian and unpadded:
+//   0x00  uint64  Unix time the clock was saved at
+//   0x08  uint16  minute of the day
+//   0x0A  uint16  day counter
+//   0x0C  uint16  alarm minute
+//   0x0E  uint16  alarm day
+//   0x10  uint8   alarm enabled
+// The alarm is not emulated: it is written as zero and ignored on load.
+// Older HuC3 saves from this emulator carry a 44/48-byte footer with the
+// minute of day as H:M and the day counter in DL and DH bits 0-3; they
+// are still read, and rewritten in the 17-byte layout the next time the
+// save is written.
+// Source: https://github.com/LIJI32/SameBoy/blob/master/Core/gb.c
+// -----------------------------
+
+const (
+	rtcFooterSize   = 48
+	rtcFooterSize32 = 44
+	rtcFooterRegs   = 5
+
+	huc3FooterSize    = 17
+	huc3FooterMinutes = 0x08
+	huc3FooterDays    = 0x0A
+)
+
+// rtcChip is a cartridge clock whose state goes into the save footer.
+type rtcChip interface {
+	// footer brings the clock up to date and encodes it.
+	footer() []byte
+	// loadFooter restores the clock. Unless freeze is set, the time since
+	// the save's timestamp is applied on the next update.
+	loadFooter(footer []byte, freeze bool)
+	// dirty reports whether the game set the clock since the last footer.
+	dirty() bool
+	// validFooter reports whether size is a footer size the chip reads.
+	validFooter(size int) bool
+}
+
+// clockMapper is implemented by mappers that can carry a clock chip.
+type clockMapper interface {
+	clockChip() rtcChip
+}
+
+// clockChip returns the cartridge's clock, or nil without one.
+func (c *Cart) clockChip() rtcChip {
+	if m, ok := c.mapper.(clockMapper); ok {
+		return m.clockChip()
+	}
+	return nil
+}
+
+// encodeRTCFooter lays out two register sets, each as get() would return
+// them for 0x08-0x0C, and the save time.
+func encodeRTCFooter(live, latched [rtcFooterRegs]byte, saved time.Time) []byte {
+	b := make([]byte, rtcFooterSize)
+	for i := 0; i < rtcFooterRegs; i++ {
+		binary.LittleEndian.PutUint32(b[i*4:], uint32(live[i]))
+		binary.LittleEndian.PutUint32(b[(rtcFooterRegs+i)*4:], uint32(latched[i]))
+	}
+	binary.LittleEndian.PutUint64(b[rtcFooterRegs*8:], uint64(saved.Unix()))
+	return b
+}
+
+// decodeRTCFooter is the inverse of encodeRTCFooter, accepting both footer
+// sizes.
+func decodeRTCFooter(b []byte) (live, latched [rtcFooterRegs]byte, saved time.Time) {
+	for i := 0; i < rtcFooterRegs; i++ {
+		live[i] = byte(binary.LittleEndian.Uint32(b[i*4:]))
+		latched[i] = byte(binary.LittleEndian.Uint32(b[(rtcFooterRegs+i)*4:]))
+	}
+	var ts int64
+	if len(b) == rtcFooterSize32 {
+		ts = int64(binary.LittleEndian.Uint32(b[rtcFooterRegs*8:]))
+	} else {
+		ts = int64(binary.LittleEndian.Uint64(b[rtcFooterRegs*8:]))
+	}
+	return live, latched, time.Unix(ts, 0)
+}
+
+// resumeFrom picks the time a restored clock continues from: the save's
+// timestamp, or now when frozen or when the timestamp is missing.
+func resumeFrom(saved, now time.Time, freeze bool) time.Time {
+	if freeze || saved.Unix() == 0 || saved.After(now) {
+		return now
+	}
+	return saved
+}
+
+func (regs *rtcRegisters) footerRegs() [rtcFooterRegs]byte {
+	var out [rtcFooterRegs]byte
+	for i := range out {
+		out[i] = regs.get(rtcSeconds + byte(i))
+	}
+	out[4] &= rtcDayBit8 | rtcHalt | rtcCarry
+	return out
+}
+
+func (regs *rtcRegisters) setFooterRegs(in [rtcFooterRegs]byte) {
+	for i, v := range in {
+		regs.set(rtcSeconds+byte(i), v)
+	}
+}
+
+func (r *rtc) footer() []byte {
+	r.update()
+	r.written = false
+	return encodeRTCFooter(r.live.footerRegs(), r.latched.footerRegs(), r.last)
+}
+
+func (r *rtc) loadFooter(footer []byte, freeze bool) {
+	live, latched, saved := decodeRTCFooter(footer)
+	r.live.setFooterRegs(live)
+	r.latched.setFooterRegs(latched)
+	r.last = resumeFrom(saved, r.clock.Now(), freeze)
+}
+
+func (r *rtc) dirty() bool {
+	return r.written
+}
+
+func (r *rtc) validFooter(size int) bool {
+	return size == rtcFooterSize || size == rtcFooterSize32
+}
+
+func (r *huc3RTC) footer() []byte {
+	r.update()
+	r.written = false
+	b := make([]byte, huc3FooterSize)
+	binary.LittleEndian.PutUint64(b, uint64(r.last.Unix()))
+	binary.LittleEndian.PutUint16(b[huc3FooterMinutes:], uint16(r.minutes))
+	binary.LittleEndian.PutUint16(b[huc3FooterDays:], uint16(r.days))
+	return b
+}
+
+func (r *huc3RTC) loadFooter(footer []byte, freeze bool) {
+	if len(footer) != huc3FooterSize {
+		r.loadLegacyFooter(footer, freeze)
+		return
+	}
+	saved := time.Unix(int64(binary.LittleEndian.Uint64(footer)), 0)
+	r.minutes = int(binary.LittleEndian.Uint16(footer[huc3FooterMinutes:])) % minutesPerDay
+	r.days = int(binary.LittleEndian.Uint16(footer[huc3FooterDays:]) & 0xFFF)
+	r.last = resumeFrom(saved, r.clock.Now(), freeze)
+}
+
+func (r *huc3RTC) dirty() bool {
+	return r.written
+}
+
+// loadLegacyFooter reads the MBC3-style footer older saves used for HuC3.
+func (r *huc3RTC) loadLegacyFooter(footer []byte, freeze bool) {
+	live, _, saved := decodeRTCFooter(footer)
+	r.minutes = (int(live[2])*60 + int(live[1])) % minutesPerDay
+	r.days = int(live[3]) | int(live[4]&0x0F)<<8
+	r.last = resumeFrom(saved, r.clock.Now(), freeze)
+}
+
+func (r *huc3RTC) validFooter(size int) bool {
+	return size == huc3FooterSize || size == rtcFooterSize || size == rtcFooterSize32
+}
+
+func (c *mbc3) clockChip() rtcChip {
+	if c.rtc == nil {
+		return nil
+	}
+	return c.rtc
+}
+
+func (c *huc3) clockChip() rtcChip {
+	return c.rtc
+}
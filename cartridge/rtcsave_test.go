@@ -0,0 +1,209 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+// openRTCCart builds an MBC3+TIMER+RAM+BATTERY cart (8KiB RAM) on clock and
+// opens its save at path.
+func openRTCCart(t *testing.T, path string, clock Clock, freeze bool) (*memory.MMU, *SaveFile) {
+	t.Helper()
+	cart, err := NewWithOptions(carttest.Builder{Type: 0x10, ROMSize: 0x01, RAMSize: 0x02}.Build(), Options{Clock: clock, FreezeRTC: freeze})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenSave(cart, path)
+	if err != nil {
+		t.Fatalf("OpenSave() error: %v", err)
+	}
+	mmu := memory.NewMMU(cart)
+	mmu.Write(0x0000, 0x0A)
+	return mmu, s
+}
+
+func TestRTCFooter_Layout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	clock := &fakeClock{now: time.Unix(1_000_000_000, 0)}
+	mmu, s := openRTCCart(t, path, clock, false)
+
+	mmu.Write(0x4000, rtcHours)
+	mmu.Write(0xA000, 5)
+	mmu.Write(0x4000, rtcDayHigh)
+	mmu.Write(0xA000, rtcDayBit8)
+	clock.advance(7 * time.Second)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 8*1024+rtcFooterSize {
+		t.Fatalf("save is %d bytes; want RAM + %d", len(data), rtcFooterSize)
+	}
+	footer := data[8*1024:]
+	want := []uint32{7, 0, 5, 0, rtcDayBit8}
+	for i, w := range want {
+		if got := binary.LittleEndian.Uint32(footer[i*4:]); got != w {
+			t.Errorf("live register %d = %d; want %d", i, got, w)
+		}
+	}
+	if got := binary.LittleEndian.Uint64(footer[40:]); got != 1_000_000_007 {
+		t.Errorf("timestamp = %d; want 1000000007", got)
+	}
+}
+
+func TestRTCFooter_ElapsedTime(t *testing.T) {
+	for _, freeze := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "game.sav")
+		clock := &fakeClock{now: time.Unix(1_000_000_000, 0)}
+		_, s := openRTCCart(t, path, clock, freeze)
+		if err := s.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		clock.advance(2 * time.Hour)
+		mmu, _ := openRTCCart(t, path, clock, freeze)
+		latch(mmu)
+		want := byte(2)
+		if freeze {
+			want = 0
+		}
+		if got := readRTC(mmu, rtcHours); got != want {
+			t.Errorf("freeze=%v: hours = %d; want %d", freeze, got, want)
+		}
+	}
+}
+
+func TestRTCFooter_Legacy44(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	data := make([]byte, 8*1024+rtcFooterSize32)
+	footer := data[8*1024:]
+	binary.LittleEndian.PutUint32(footer[4:], 30)     // live minutes
+	binary.LittleEndian.PutUint32(footer[24:], 15)    // latched minutes
+	binary.LittleEndian.PutUint32(footer[40:], 1_000) // 32-bit timestamp
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &fakeClock{now: time.Unix(1_000+60, 0)}
+	mmu, _ := openRTCCart(t, path, clock, false)
+	if got := readRTC(mmu, rtcMinutes); got != 15 {
+		t.Errorf("latched minutes = %d; want 15", got)
+	}
+	latch(mmu)
+	if got := readRTC(mmu, rtcMinutes); got != 31 {
+		t.Errorf("live minutes = %d; want 31", got)
+	}
+}
+
+func TestRTCFooter_HuC3(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	clock := &fakeClock{now: time.Unix(1_000_000_000, 0)}
+	open := func() (*memory.MMU, *SaveFile) {
+		cart, err := NewWithOptions(carttest.Builder{Type: 0xFE, ROMSize: 0x02, RAMSize: 0x03}.Build(), Options{Clock: clock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := OpenSave(cart, path)
+		if err != nil {
+			t.Fatalf("OpenSave() error: %v", err)
+		}
+		return memory.NewMMU(cart), s
+	}
+
+	_, s := open()
+	clock.advance(3*24*time.Hour + 90*time.Minute)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 32*1024+huc3FooterSize {
+		t.Fatalf("save is %d bytes; want RAM + %d", len(data), huc3FooterSize)
+	}
+	footer := data[32*1024:]
+	if got := binary.LittleEndian.Uint64(footer); got != 1_000_000_000+3*24*3600+90*60 {
+		t.Errorf("timestamp = %d", got)
+	}
+	if got := binary.LittleEndian.Uint16(footer[huc3FooterMinutes:]); got != 90 {
+		t.Errorf("minutes = %d; want 90", got)
+	}
+	if got := binary.LittleEndian.Uint16(footer[huc3FooterDays:]); got != 3 {
+		t.Errorf("days = %d; want 3", got)
+	}
+	clock.advance(24 * time.Hour)
+
+	mmu, _ := open()
+	huc3Do(mmu, 0x60)
+	huc3Seek(mmu, 0x03)
+	if got := huc3Do(mmu, 0x10) & 0x0F; got != 4 {
+		t.Errorf("days = %d; want 4", got)
+	}
+}
+
+func TestRTCFooter_HuC3Legacy48(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	data := make([]byte, 32*1024+rtcFooterSize)
+	footer := data[32*1024:]
+	binary.LittleEndian.PutUint32(footer[4:], 30)             // minutes
+	binary.LittleEndian.PutUint32(footer[8:], 1)              // hours
+	binary.LittleEndian.PutUint32(footer[12:], 5)             // day counter
+	binary.LittleEndian.PutUint64(footer[40:], 1_000_000_000) // saved at
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &fakeClock{now: time.Unix(1_000_000_000+2*60, 0)}
+	cart, err := NewWithOptions(carttest.Builder{Type: 0xFE, ROMSize: 0x02, RAMSize: 0x03}.Build(), Options{Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenSave(cart, path)
+	if err != nil {
+		t.Fatalf("OpenSave() error: %v", err)
+	}
+	mmu := memory.NewMMU(cart)
+	huc3Do(mmu, 0x60)
+	huc3Seek(mmu, 0x00)
+	var value int
+	for i := 0; i < 6; i++ {
+		value |= int(huc3Do(mmu, 0x10)&0x0F) << (4 * i)
+	}
+	if minutes, days := value&0xFFF, value>>12; minutes != 92 || days != 5 {
+		t.Errorf("clock = day %d minute %d; want day 5 minute 92", days, minutes)
+	}
+
+	mmu.Write(0x0000, 0x0A)
+	mmu.Write(0xA000, 0x01) // dirty the RAM so the save is rewritten
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 32*1024+huc3FooterSize {
+		t.Errorf("rewritten save is %d bytes; want RAM + %d", len(data), huc3FooterSize)
+	}
+}
+
+func TestRTCFooter_RejectedWithoutClock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	if err := os.WriteFile(path, make([]byte, 8*1024+rtcFooterSize), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cart, _ := New(carttest.Builder{Type: 0x03, ROMSize: 0x01, RAMSize: 0x02}.Build())
+	if _, err := OpenSave(cart, path); err == nil {
+		t.Error("OpenSave() accepted an RTC footer on a cart without a clock")
+	}
+}
@@ -0,0 +1,151 @@
+package cartridge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Save Files
+// -----------------------------
+// Battery-backed RAM is stored raw next to the ROM with a .sav extension
+// (tetris.gb -> tetris.sav), bank 0 first, the format every other emulator
+// reads. MBC2 saves are the 512 nibbles one per byte, MBC7 saves the
+// EEPROM image. MBC3 and HuC3 carts with a clock add an RTC footer
+// described in rtcsave.go.
+//
+// Writes go to a temporary file in the same directory which is then
+// renamed over the save, so a crash leaves either the old or the new save,
+// never half of each.
+// -----------------------------
+
+// SaveExtension is the file extension of battery saves.
+const SaveExtension = ".sav"
+
+// DefaultFlushInterval is how often Update writes a changed save.
+const DefaultFlushInterval = 10 * time.Second
+
+// SavePathForROM returns the save file path that belongs to romPath.
+func SavePathForROM(romPath string) string {
+	return strings.TrimSuffix(romPath, filepath.Ext(romPath)) + SaveExtension
+}
+
+// HasBattery reports whether the cartridge keeps its RAM with the power off.
+func (c *Cart) HasBattery() bool {
+	return c.Header.Type.Battery && (len(c.RAM()) > 0 || c.clockChip() != nil)
+}
+
+// SaveFile ties a battery-backed cartridge to its save file. Flush writes
+// on demand (and on exit); Update, called from the emulation loop, writes
+// every FlushInterval while the RAM keeps changing. Only changed RAM is
+// ever written. On carts without a battery every method is a no-op.
+type SaveFile struct {
+	Path          string
+	FlushInterval time.Duration
+
+	cart      *Cart
+	saved     []byte // RAM contents as of the last load or flush
+	lastFlush time.Time
+}
+
+// OpenSave loads the save at path into cart and returns a SaveFile that
+// keeps it up to date. A missing save is not an error: the game starts
+// with blank RAM and the file appears once the game writes to it.
+func OpenSave(cart *Cart, path string) (*SaveFile, error) {
+	s := &SaveFile{Path: path, FlushInterval: DefaultFlushInterval, cart: cart, lastFlush: time.Now()}
+	if !cart.HasBattery() {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	ram, chip := cart.RAM(), cart.clockChip()
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	case len(data) == len(ram):
+		copy(ram, data)
+	case chip != nil && len(data) > len(ram) && chip.validFooter(len(data)-len(ram)):
+		copy(ram, data)
+		chip.loadFooter(data[len(ram):], cart.freezeRTC)
+	default:
+		return nil, fmt.Errorf("%s: %w: %s has %d bytes of RAM, save has %d", path, ErrSaveSize, cart.Header.Title, len(ram), len(data))
+	}
+	// A new clock cart is written on the first flush, so the clock keeps
+	// counting from its first power on rather than the first RAM write.
+	if data != nil || chip == nil {
+		s.saved = bytes.Clone(ram)
+	}
+	return s, nil
+}
+
+// Update flushes if FlushInterval has passed since the last flush. now is
+// usually time.Now(), tests pass their own.
+func (s *SaveFile) Update(now time.Time) error {
+	if now.Sub(s.lastFlush) < s.FlushInterval {
+		return nil
+	}
+	s.lastFlush = now
+	return s.Flush()
+}
+
+// Flush writes the RAM to disk if it changed since the last flush, or if
+// the game set its clock. The RTC footer is refreshed on every write.
+func (s *SaveFile) Flush() error {
+	if !s.cart.HasBattery() {
+		return nil
+	}
+	ram, chip := s.cart.RAM(), s.cart.clockChip()
+	if s.saved != nil && bytes.Equal(ram, s.saved) && (chip == nil || !chip.dirty()) {
+		return nil
+	}
+	data := ram
+	if chip != nil {
+		data = append(bytes.Clone(ram), chip.footer()...)
+	}
+	if err := writeFileAtomic(s.Path, data); err != nil {
+		return err
+	}
+	s.saved = bytes.Clone(ram)
+	return nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file and a
+// rename, syncing before the rename so the new name never points at
+// unwritten blocks.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package cartridge
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+func TestSavePathForROM(t *testing.T) {
+	if got := SavePathForROM("games/tetris.gb"); got != "games/tetris.sav" {
+		t.Errorf("SavePathForROM() = %q; want games/tetris.sav", got)
+	}
+}
+
+func TestSaveFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	cart, err := New(carttest.Builder{Type: 0x03, ROMSize: 0x01, RAMSize: 0x02}.Build()) // MBC1+RAM+BATTERY, 8KiB
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenSave(cart, path)
+	if err != nil {
+		t.Fatalf("OpenSave() error: %v", err)
+	}
+
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("blank RAM was saved, Stat() error = %v", err)
+	}
+
+	cart.Write(0x0000, 0x0A)
+	cart.Write(0xA123, 0x42)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	again, _ := New(carttest.Builder{Type: 0x03, ROMSize: 0x01, RAMSize: 0x02}.Build())
+	if _, err := OpenSave(again, path); err != nil {
+		t.Fatalf("reopen error: %v", err)
+	}
+	if !bytes.Equal(again.RAM(), cart.RAM()) {
+		t.Error("reloaded RAM differs from the saved RAM")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries; want only the save", len(entries))
+	}
+}
+
+func TestSaveFile_SizeMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	if err := os.WriteFile(path, make([]byte, 2048), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cart, _ := New(carttest.Builder{Type: 0x03, ROMSize: 0x01, RAMSize: 0x02}.Build())
+	if _, err := OpenSave(cart, path); !errors.Is(err, ErrSaveSize) {
+		t.Errorf("OpenSave() error = %v; want ErrSaveSize", err)
+	}
+}
+
+func TestSaveFile_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	cart, _ := New(carttest.Builder{Type: 0x03, ROMSize: 0x01, RAMSize: 0x02}.Build())
+	s, _ := OpenSave(cart, path)
+	start := time.Now()
+	s.lastFlush = start
+
+	cart.Write(0x0000, 0x0A)
+	cart.Write(0xA000, 0x01)
+	if err := s.Update(start.Add(s.FlushInterval / 2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("Update flushed before the interval")
+	}
+	if err := s.Update(start.Add(s.FlushInterval)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Update did not flush after the interval: %v", err)
+	}
+}
+
+func TestSaveFile_NoBattery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.sav")
+	cart, _ := New(carttest.Builder{Type: 0x02, ROMSize: 0x01, RAMSize: 0x02}.Build()) // MBC1+RAM, no battery
+	s, err := OpenSave(cart, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cart.Write(0x0000, 0x0A)
+	cart.Write(0xA000, 0x01)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("cart without battery wrote a save")
+	}
+}
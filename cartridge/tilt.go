@@ -0,0 +1,36 @@
+package cartridge
+
+import "sync"
+
+// TiltSource feeds the MBC7 accelerometer. Tilt returns the acceleration on
+// each axis in g, roughly -1..1: positive x is tilted right, positive y is
+// tilted towards the player. It is polled when the game latches the sensor.
+type TiltSource interface {
+	Tilt() (x, y float64)
+}
+
+// TiltFunc adapts a function (a script, a mouse handler) to TiltSource.
+type TiltFunc func() (x, y float64)
+
+func (f TiltFunc) Tilt() (x, y float64) {
+	return f()
+}
+
+// FixedTilt holds the last tilt set by the frontend, e.g. from arrow keys.
+// Set may be called from the UI goroutine while the emulator polls Tilt.
+type FixedTilt struct {
+	mu   sync.Mutex
+	x, y float64
+}
+
+func (t *FixedTilt) Set(x, y float64) {
+	t.mu.Lock()
+	t.x, t.y = x, y
+	t.mu.Unlock()
+}
+
+func (t *FixedTilt) Tilt() (x, y float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.x, t.y
+}
@@ -0,0 +1,186 @@
+package cheat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Code Formats
+// -----------------------------
+// Game Genie  ABC-DEF or ABC-DEF-GHI (hex digits)
+//   value   = AB
+//   address = (F xor 0xF) CDE              -> always 0x0000-0x7FFF (ROM)
+//   compare = (GI ror 2) xor 0xBA, H unused
+//   The patch only applies while the ROM byte equals compare, which is how a
+//   code targets one bank of a banked ROM.
+//
+// GameShark   TTVVLLHH (hex digits)
+//   TT      = 01 plain write, 8x cart RAM bank x, 9x WRAM bank x (CGB)
+//   VV      = value
+//   HHLL    = address (stored little-endian in the code)
+//
+// Several codes that belong together can be joined with '+'.
+// Source: https://gbdev.gg8.se/wiki/articles/Gameshark_and_Game_Genie
+// -----------------------------
+
+// ErrInvalidCode is wrapped by every parse error.
+var ErrInvalidCode = errors.New("invalid cheat code")
+
+// Kind says which device a code is for.
+type Kind int
+
+const (
+	GameGenie Kind = iota
+	GameShark
+)
+
+func (k Kind) String() string {
+	if k == GameShark {
+		return "GameShark"
+	}
+	return "Game Genie"
+}
+
+// romPatch is one decoded Game Genie code.
+type romPatch struct {
+	addr       uint16
+	value      byte
+	compare    byte
+	hasCompare bool
+}
+
+// ramWrite is one decoded GameShark code.
+type ramWrite struct {
+	addr  uint16
+	value byte
+	bank  int // -1 for "whatever is mapped"
+}
+
+// Cheat is one line of a cheat file: a code (or '+' joined group of codes)
+// and its description.
+type Cheat struct {
+	Code        string
+	Description string
+	Enabled     bool
+	Kind        Kind
+
+	patches []romPatch
+	writes  []ramWrite
+}
+
+// Parse decodes code, which may be several codes of the same kind joined
+// with '+'. The returned cheat is enabled.
+func Parse(code, description string) (*Cheat, error) {
+	c := &Cheat{Code: strings.ToUpper(strings.TrimSpace(code)), Description: description, Enabled: true}
+	if c.Code == "" {
+		return nil, fmt.Errorf("%w: empty code", ErrInvalidCode)
+	}
+	for i, part := range strings.Split(c.Code, "+") {
+		kind, err := parsePart(c, part)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			c.Kind = kind
+		} else if kind != c.Kind {
+			return nil, fmt.Errorf("%w: %q mixes Game Genie and GameShark codes", ErrInvalidCode, c.Code)
+		}
+	}
+	return c, nil
+}
+
+func parsePart(c *Cheat, part string) (Kind, error) {
+	if strings.Contains(part, "-") {
+		patch, err := parseGameGenie(part)
+		if err != nil {
+			return 0, err
+		}
+		c.patches = append(c.patches, patch)
+		return GameGenie, nil
+	}
+	write, err := parseGameShark(part)
+	if err != nil {
+		return 0, err
+	}
+	c.writes = append(c.writes, write)
+	return GameShark, nil
+}
+
+func parseGameGenie(code string) (romPatch, error) {
+	groups := strings.Split(code, "-")
+	if (len(groups) != 2 && len(groups) != 3) || len(groups[0]) != 3 || len(groups[1]) != 3 ||
+		(len(groups) == 3 && len(groups[2]) != 3) {
+		return romPatch{}, fmt.Errorf("%w: Game Genie code %q must look like ABC-DEF or ABC-DEF-GHI", ErrInvalidCode, code)
+	}
+	digits, err := hexDigits(strings.Join(groups, ""))
+	if err != nil {
+		return romPatch{}, fmt.Errorf("%w: Game Genie code %q: %v", ErrInvalidCode, code, err)
+	}
+
+	patch := romPatch{
+		value: digits[0]<<4 | digits[1],
+		addr: uint16(digits[5]^0x0F)<<12 | uint16(digits[2])<<8 |
+			uint16(digits[3])<<4 | uint16(digits[4]),
+	}
+	if patch.addr > 0x7FFF {
+		return romPatch{}, fmt.Errorf("%w: Game Genie code %q targets 0x%04X, outside ROM", ErrInvalidCode, code, patch.addr)
+	}
+	if len(digits) == 9 {
+		gi := digits[6]<<4 | digits[8]
+		patch.compare = (gi>>2 | gi<<6) ^ 0xBA
+		patch.hasCompare = true
+	}
+	return patch, nil
+}
+
+func parseGameShark(code string) (ramWrite, error) {
+	if len(code) != 8 {
+		return ramWrite{}, fmt.Errorf("%w: GameShark code %q must be 8 hex digits", ErrInvalidCode, code)
+	}
+	raw, err := strconv.ParseUint(code, 16, 32)
+	if err != nil {
+		return ramWrite{}, fmt.Errorf("%w: GameShark code %q is not hex", ErrInvalidCode, code)
+	}
+	typ := byte(raw >> 24)
+	write := ramWrite{
+		value: byte(raw >> 16),
+		addr:  uint16(raw&0xFF)<<8 | uint16(raw>>8&0xFF),
+		bank:  -1,
+	}
+
+	switch {
+	case typ == 0x01:
+		// plain write into whatever is mapped
+	case typ&0xF0 == 0x80:
+		write.bank = int(typ & 0x0F)
+		if write.addr < 0xA000 || write.addr > 0xBFFF {
+			return ramWrite{}, fmt.Errorf("%w: GameShark code %q selects a cart RAM bank but targets 0x%04X", ErrInvalidCode, code, write.addr)
+		}
+	case typ&0xF0 == 0x90:
+		write.bank = int(typ & 0x07)
+		if write.addr < 0xD000 || write.addr > 0xDFFF {
+			return ramWrite{}, fmt.Errorf("%w: GameShark code %q selects a WRAM bank but targets 0x%04X", ErrInvalidCode, code, write.addr)
+		}
+	default:
+		return ramWrite{}, fmt.Errorf("%w: GameShark code %q has unknown type 0x%02X", ErrInvalidCode, code, typ)
+	}
+
+	if write.addr < 0x8000 {
+		return ramWrite{}, fmt.Errorf("%w: GameShark code %q targets ROM address 0x%04X", ErrInvalidCode, code, write.addr)
+	}
+	return write, nil
+}
+
+func hexDigits(s string) ([]byte, error) {
+	digits := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		d, err := strconv.ParseUint(s[i:i+1], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a hex digit", s[i])
+		}
+		digits[i] = byte(d)
+	}
+	return digits, nil
+}
@@ -0,0 +1,81 @@
+package cheat
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse_GameGenie(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		addr       uint16
+		value      byte
+		compare    byte
+		hasCompare bool
+	}{
+		{"With compare", "3CA-17B-6FE", 0x4A17, 0x3C, 0x21, true},
+		{"Without compare", "00A-17B", 0x4A17, 0x00, 0, false},
+		{"Lowercase", "3ca-17b-6fe", 0x4A17, 0x3C, 0x21, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(tt.code, "")
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.code, err)
+			}
+			if c.Kind != GameGenie || len(c.patches) != 1 {
+				t.Fatalf("Parse(%q) = %+v; want one Game Genie patch", tt.code, c)
+			}
+			p := c.patches[0]
+			if p.addr != tt.addr || p.value != tt.value || p.hasCompare != tt.hasCompare || p.compare != tt.compare {
+				t.Errorf("patch = %+v; want addr 0x%X value 0x%X compare 0x%X (%v)", p, tt.addr, tt.value, tt.compare, tt.hasCompare)
+			}
+		})
+	}
+}
+
+func TestParse_GameShark(t *testing.T) {
+	tests := []struct {
+		name  string
+		code  string
+		addr  uint16
+		value byte
+		bank  int
+	}{
+		{"Plain write", "010AD0C1", 0xC1D0, 0x0A, -1},
+		{"Cart RAM bank 2", "82FF00A0", 0xA000, 0xFF, 2},
+		{"WRAM bank 5", "95630FD3", 0xD30F, 0x63, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(tt.code, "")
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.code, err)
+			}
+			w := c.writes[0]
+			if c.Kind != GameShark || w.addr != tt.addr || w.value != tt.value || w.bank != tt.bank {
+				t.Errorf("write = %+v; want addr 0x%X value 0x%X bank %d", w, tt.addr, tt.value, tt.bank)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	codes := []string{
+		"",
+		"3CA-17B-6F",       // short compare group
+		"3CA17B",           // missing dash, not 8 digits
+		"ZZA-17B",          // not hex
+		"3CA-173",          // address 0xCA17, outside ROM
+		"020AD0C1",         // unknown GameShark type
+		"82FF00C0",         // cart RAM bank on a WRAM address
+		"010A0040",         // GameShark write into ROM
+		"010AD0C1+00A-17B", // mixed kinds
+	}
+	for _, code := range codes {
+		if _, err := Parse(code, ""); !errors.Is(err, ErrInvalidCode) {
+			t.Errorf("Parse(%q) error = %v; want ErrInvalidCode", code, err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cheat
+
+import "github.com/leaf/gameboy/memory"
+
+// Engine applies cheats to a running MMU.
+//   - Game Genie codes are served by a cartridge decorator, so every ROM
+//     read (CPU or debugger Peek) sees the patched byte.
+//   - GameShark codes are written once per frame by ApplyFrame, the same
+//     way the real device did from its VBlank hook.
+type Engine struct {
+	// Path is the cheat file Save writes to; Open sets it.
+	Path string
+
+	mmu    *memory.MMU
+	cart   *patchedCartridge
+	cheats []*Cheat
+}
+
+// NewEngine puts the cheat decorator in front of the MMU's cartridge.
+func NewEngine(mmu *memory.MMU) *Engine {
+	e := &Engine{mmu: mmu}
+	e.cart = &patchedCartridge{cart: mmu.Cartridge()}
+	mmu.SetCartridge(e.cart)
+	return e
+}
+
+// Add parses code and adds it enabled.
+func (e *Engine) Add(code, description string) (*Cheat, error) {
+	c, err := Parse(code, description)
+	if err != nil {
+		return nil, err
+	}
+	e.AddCheat(c)
+	return c, nil
+}
+
+// AddCheat adds an already parsed cheat, keeping its Enabled state.
+func (e *Engine) AddCheat(c *Cheat) {
+	e.cheats = append(e.cheats, c)
+	e.refresh()
+}
+
+// Remove deletes the cheat at index i.
+func (e *Engine) Remove(i int) {
+	if i < 0 || i >= len(e.cheats) {
+		return
+	}
+	e.cheats = append(e.cheats[:i], e.cheats[i+1:]...)
+	e.refresh()
+}
+
+// SetEnabled turns the cheat at index i on or off.
+func (e *Engine) SetEnabled(i int, enabled bool) {
+	if i < 0 || i >= len(e.cheats) {
+		return
+	}
+	e.cheats[i].Enabled = enabled
+	e.refresh()
+}
+
+// Cheats returns the cheats in the order they were added.
+func (e *Engine) Cheats() []*Cheat {
+	return e.cheats
+}
+
+// ApplyFrame performs every enabled GameShark write. Call it once per frame,
+// at VBlank.
+func (e *Engine) ApplyFrame() {
+	for _, c := range e.cheats {
+		if !c.Enabled {
+			continue
+		}
+		for _, w := range c.writes {
+			if w.bank < 0 {
+				e.mmu.Poke(w.addr, w.value)
+			} else {
+				e.mmu.PokeBank(w.bank, w.addr, w.value)
+			}
+		}
+	}
+}
+
+// refresh rebuilds the active Game Genie patch list and remaps the cartridge
+// pages, since patched pages can no longer be read straight from ROM.
+func (e *Engine) refresh() {
+	patches := make(map[uint16][]romPatch)
+	var pages [romPages]bool
+	for _, c := range e.cheats {
+		if !c.Enabled {
+			continue
+		}
+		for _, p := range c.patches {
+			patches[p.addr] = append(patches[p.addr], p)
+			pages[p.addr>>8] = true
+		}
+	}
+	e.cart.patches, e.cart.pages = patches, pages
+	e.mmu.RemapCartridge()
+}
+
+// romPages is the number of 256-byte pages in the ROM area 0x0000-0x7FFF.
+const romPages = 0x80
+
+// patchedCartridge applies Game Genie patches on top of the real cartridge.
+type patchedCartridge struct {
+	cart    memory.Cartridge
+	patches map[uint16][]romPatch
+	pages   [romPages]bool // ROM pages holding at least one patch
+}
+
+func (p *patchedCartridge) Read(addr uint16) byte {
+	return p.patch(addr, p.cart.Read(addr))
+}
+
+func (p *patchedCartridge) Write(addr uint16, data byte) {
+	p.cart.Write(addr, data)
+}
+
+func (p *patchedCartridge) Bank(addr uint16) int {
+	if cart, ok := p.cart.(memory.BankedCartridge); ok {
+		return cart.Bank(addr)
+	}
+	if addr >= 0x4000 && addr <= 0x7FFF {
+		return 1
+	}
+	return 0
+}
+
+func (p *patchedCartridge) PeekBank(bank int, addr uint16) byte {
+	cart, ok := p.cart.(memory.BankedCartridge)
+	if !ok {
+		return p.Read(addr)
+	}
+	return p.patch(addr, cart.PeekBank(bank, addr))
+}
+
+func (p *patchedCartridge) PokeBank(bank int, addr uint16, data byte) {
+	if cart, ok := p.cart.(memory.BankedCartridge); ok {
+		cart.PokeBank(bank, addr, data)
+	}
+}
+
+func (p *patchedCartridge) RAMSize() int {
+	if cart, ok := p.cart.(memory.RAMBankedCartridge); ok {
+		return cart.RAMSize()
+	}
+	return memory.CartridgeRAMEnd - memory.CartridgeRAMStart + 1
+}
+
+// PeekRAM falls back to PeekBank for cartridges that can't copy banks.
+func (p *patchedCartridge) PeekRAM(bank int, dst []byte) int {
+	if cart, ok := p.cart.(memory.RAMBankedCartridge); ok {
+		return cart.PeekRAM(bank, dst)
+	}
+	n := min(len(dst), p.RAMSize())
+	for i := range dst[:n] {
+		dst[i] = p.PeekBank(bank, memory.CartridgeRAMStart+uint16(i))
+	}
+	return n
+}
+
+// ReadPage hides pages holding a patch so the MMU falls back to Read.
+func (p *patchedCartridge) ReadPage(addr uint16) []byte {
+	cart, ok := p.cart.(memory.PagedCartridge)
+	if !ok {
+		return nil
+	}
+	if addr <= 0x7FFF && p.pages[addr>>8] {
+		return nil
+	}
+	return cart.ReadPage(addr)
+}
+
+// patch returns the patched value for a ROM byte, honouring compare values.
+func (p *patchedCartridge) patch(addr uint16, value byte) byte {
+	if addr > 0x7FFF {
+		return value
+	}
+	for _, patch := range p.patches[addr] {
+		if !patch.hasCompare || patch.compare == value {
+			return patch.value
+		}
+	}
+	return value
+}
@@ -0,0 +1,139 @@
+package cheat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+	"github.com/leaf/gameboy/memory"
+)
+
+func TestEngine_GameGeniePatchesROM(t *testing.T) {
+	cart := (&carttest.Builder{}).At(0x4A17, 0x21, 0x55).Cartridge()
+	mmu := memory.NewMMU(cart)
+	engine := NewEngine(mmu)
+
+	if _, err := engine.Add("3CA-17B-6FE", "compare matches"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mmu.Read(0x4A17); got != 0x3C {
+		t.Errorf("Read(0x4A17) = 0x%X; want patched 0x3C", got)
+	}
+	if got := mmu.Read(0x4A18); got != 0x55 {
+		t.Errorf("Read(0x4A18) = 0x%X; want untouched 0x55", got)
+	}
+
+	engine.SetEnabled(0, false)
+	if got := mmu.Read(0x4A17); got != 0x21 {
+		t.Errorf("disabled cheat still patches, Read(0x4A17) = 0x%X", got)
+	}
+
+	cart.ROM[0x4A17] = 0x22
+	engine.SetEnabled(0, true)
+	if got := mmu.Read(0x4A17); got != 0x22 {
+		t.Errorf("patch applied although compare 0x21 != ROM 0x22, got 0x%X", got)
+	}
+}
+
+func TestEngine_GameSharkWritesEveryFrame(t *testing.T) {
+	mmu := memory.NewMMU(carttest.Builder{}.Cartridge())
+	mmu.SetCGB(true)
+	engine := NewEngine(mmu)
+	engine.Add("0163A0C0", "plain")
+	engine.Add("93770FD0", "WRAM bank 3")
+
+	mmu.Write(0xC0A0, 0x00)
+	engine.ApplyFrame()
+	if got := mmu.Read(0xC0A0); got != 0x63 {
+		t.Errorf("Read(0xC0A0) = 0x%X; want 0x63", got)
+	}
+	if got := mmu.PeekBank(3, 0xD00F); got != 0x77 {
+		t.Errorf("PeekBank(3, 0xD00F) = 0x%X; want 0x77", got)
+	}
+	if got := mmu.Read(0xD00F); got != 0x00 {
+		t.Errorf("bank 1 at 0xD00F was written: 0x%X", got)
+	}
+}
+
+func TestCheatFile_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	romPath := filepath.Join(dir, "game.gb")
+	content := "# test\n01FF23C1 Infinite lives\n\n!3CA-17B-6FE Level select\n"
+	if err := os.WriteFile(filepath.Join(dir, "game.cht"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := Open(memory.NewMMU(carttest.Builder{}.Cartridge()), romPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cheats := engine.Cheats()
+	if len(cheats) != 2 || !cheats[0].Enabled || cheats[1].Enabled || cheats[1].Description != "Level select" {
+		t.Fatalf("loaded cheats = %+v", cheats)
+	}
+
+	var sb strings.Builder
+	if err := Write(&sb, cheats); err != nil {
+		t.Fatal(err)
+	}
+	if want := "01FF23C1 Infinite lives\n!3CA-17B-6FE Level select\n"; sb.String() != want {
+		t.Errorf("Write() = %q; want %q", sb.String(), want)
+	}
+}
+
+func TestCheatFile_BadLine(t *testing.T) {
+	_, err := Read(strings.NewReader("01FF23C1 ok\nXYZ broken\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Read() error = %v; want a line 2 error", err)
+	}
+}
+
+func TestCheatFile_MissingIsNotAnError(t *testing.T) {
+	engine, err := Open(memory.NewMMU(carttest.Builder{}.Cartridge()), filepath.Join(t.TempDir(), "none.gb"))
+	if err != nil {
+		t.Fatalf("Open() error = %v; want nil", err)
+	}
+	if len(engine.Cheats()) != 0 {
+		t.Errorf("Open() loaded %d cheats from a missing file", len(engine.Cheats()))
+	}
+}
+
+func TestCheatFile_SaveAndReopen(t *testing.T) {
+	romPath := filepath.Join(t.TempDir(), "game.gb")
+	newMMU := func() *memory.MMU {
+		return memory.NewMMU((&carttest.Builder{}).At(0x4A17, 0x21).Cartridge())
+	}
+
+	engine, err := Open(newMMU(), romPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Add("3CA-17B-6FE", "Level select"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Add("01FF23C1", "Infinite lives"); err != nil {
+		t.Fatal(err)
+	}
+	engine.SetEnabled(1, false)
+	if err := engine.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(romPath), "game.cht")); err != nil {
+		t.Fatalf("Save() did not write next to the ROM: %v", err)
+	}
+
+	mmu := newMMU()
+	reopened, err := Open(mmu, romPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cheats := reopened.Cheats()
+	if len(cheats) != 2 || !cheats[0].Enabled || cheats[1].Enabled || cheats[1].Description != "Infinite lives" {
+		t.Fatalf("reopened cheats = %+v", cheats)
+	}
+	if got := mmu.Read(0x4A17); got != 0x3C {
+		t.Errorf("Read(0x4A17) = 0x%X; want the reopened patch 0x3C", got)
+	}
+}
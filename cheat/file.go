@@ -0,0 +1,125 @@
+package cheat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leaf/gameboy/memory"
+)
+
+// Cheat File Format
+// -----------------------------
+// Plain text, one cheat per line, stored next to the ROM with a .cht
+// extension (tetris.gb -> tetris.cht):
+//
+//	# comments and blank lines are ignored
+//	01FF23C1 Infinite lives
+//	!00A-17B-C49 Start on level 5     <- leading '!' means disabled
+//	00A-17B-C49+01A-23F-E6E Two codes, one cheat
+//
+// The first field is the code, the rest of the line is the description.
+// -----------------------------
+
+// Extension is the file extension of cheat files.
+const Extension = ".cht"
+
+// PathForROM returns the cheat file path that belongs to romPath.
+func PathForROM(romPath string) string {
+	return strings.TrimSuffix(romPath, filepath.Ext(romPath)) + Extension
+}
+
+// Read parses a cheat file. Errors report the offending line number.
+func Read(r io.Reader) ([]*Cheat, error) {
+	var cheats []*Cheat
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		enabled := true
+		if strings.HasPrefix(text, "!") {
+			enabled = false
+			text = strings.TrimSpace(text[1:])
+		}
+		code, description, _ := strings.Cut(text, " ")
+		c, err := Parse(code, strings.TrimSpace(description))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		c.Enabled = enabled
+		cheats = append(cheats, c)
+	}
+	return cheats, scanner.Err()
+}
+
+// Write stores cheats in the cheat file format.
+func Write(w io.Writer, cheats []*Cheat) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range cheats {
+		if !c.Enabled {
+			bw.WriteString("!")
+		}
+		bw.WriteString(c.Code)
+		if c.Description != "" {
+			bw.WriteString(" " + c.Description)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// Open puts an engine in front of mmu's cartridge, loaded with the cheats
+// stored next to romPath. Call it after loading the ROM, the way
+// cartridge.OpenSave is called for the save. A missing cheat file is not an
+// error: the engine starts empty and Save creates the file.
+func Open(mmu *memory.MMU, romPath string) (*Engine, error) {
+	path := PathForROM(romPath)
+	cheats, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	e := NewEngine(mmu)
+	e.Path = path
+	e.cheats = cheats
+	e.refresh()
+	return e, nil
+}
+
+// Save writes the engine's cheats to Path.
+func (e *Engine) Save() error {
+	f, err := os.Create(e.Path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, e.cheats); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readFile reads the cheat file at path; a missing file holds no cheats.
+func readFile(path string) ([]*Cheat, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cheats, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("cheat file %s: %w", path, err)
+	}
+	return cheats, nil
+}
@@ -2,24 +2,46 @@ package cpu
 
 // MemoryBus defines the interface for memory access.
 // The CPU only needs to know how to Read and Write.
+// Peek and Poke are the debugger path: they reach the same bytes without
+// IO side effects or access locking, and the CPU itself never calls them.
 type MemoryBus interface {
 	Read(addr uint16) byte
 	Write(addr uint16, value byte)
+	Peek(addr uint16) byte
+	Poke(addr uint16, value byte)
 }
 
 type CPU struct {
 	registers *Registers
 	bus       MemoryBus
+
+	// instructionPC is the address of the instruction being executed.
+	// PC itself has already moved past the opcode by the time it runs.
+	instructionPC uint16
+}
+
+func New(bus MemoryBus) *CPU {
+	return &CPU{
+		registers: &Registers{},
+		bus:       bus,
+	}
+}
+
+// InstructionPC returns the address of the instruction currently executing,
+// which is what debuggers and watchpoints want to report.
+func (c *CPU) InstructionPC() uint16 {
+	return c.instructionPC
 }
 
 func (c *CPU) RunNextInstruction() {
+	c.instructionPC = c.registers.PC
 	opcode := c.fetch()
 	c.execute(opcode)
 }
 
 func (c *CPU) fetch() byte {
-	value := c.bus.Read(c.registers.pc)
-	c.registers.pc++
+	value := c.bus.Read(c.registers.PC)
+	c.registers.PC++
 	return value
 }
 
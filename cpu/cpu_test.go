@@ -17,6 +17,14 @@ func (m *mockMemory) Write(addr uint16, value byte) {
 	m.data[addr] = value
 }
 
+func (m *mockMemory) Peek(addr uint16) byte {
+	return m.Read(addr)
+}
+
+func (m *mockMemory) Poke(addr uint16, value byte) {
+	m.Write(addr, value)
+}
+
 func TestCPU_Fetch(t *testing.T) {
 	mockBus := &mockMemory{
 		data: map[uint16]byte{
@@ -25,7 +33,7 @@ func TestCPU_Fetch(t *testing.T) {
 	}
 
 	cpu := &CPU{
-		registers: &Registers{pc: 0x0000},
+		registers: &Registers{PC: 0x0000},
 		bus:       mockBus,
 	}
 
@@ -34,7 +42,20 @@ func TestCPU_Fetch(t *testing.T) {
 		t.Errorf("fetch() = 0x%X; want 0x42", instruction)
 	}
 
-	if cpu.registers.pc != 0x0001 {
-		t.Errorf("After fetch, PC = 0x%X; want 0x0001", cpu.registers.pc)
+	if cpu.registers.PC != 0x0001 {
+		t.Errorf("After fetch, PC = 0x%X; want 0x0001", cpu.registers.PC)
+	}
+}
+
+func TestCPU_InstructionPC(t *testing.T) {
+	cpu := New(&mockMemory{})
+	cpu.registers.PC = 0x0150
+
+	cpu.RunNextInstruction()
+	if got := cpu.InstructionPC(); got != 0x0150 {
+		t.Errorf("InstructionPC() = 0x%X; want 0x0150", got)
+	}
+	if cpu.registers.PC == 0x0150 {
+		t.Errorf("PC did not advance past the opcode")
 	}
 }
@@ -0,0 +1,76 @@
+package graphics
+
+// Background and Window
+// -----------------------------
+// Both are 32x32 maps of tile numbers (0x9800 or 0x9C00, LCDC bits 3 and 6)
+// pointing at 8x8 2bpp tiles. LCDC bit 4 picks the addressing: 1 means
+// tiles 0-255 at 0x8000, 0 means tiles -128..127 around 0x9000.
+//
+// The background scrolls by SCX/SCY and wraps around the map. The window
+// is not scrolled: it starts at screen position WX-7, WY and covers
+// everything right of and below that. Its own line counter only advances
+// on lines where it was drawn, so hiding it mid-frame resumes where it
+// left off. With LCDC bit 0 clear (DMG), both are blank (color 0).
+//
+// On CGB, VRAM bank 1 holds an attribute byte for every map entry, at the
+// same address as the tile number in bank 0:
+//
+//	bit 7    BG over OBJ: colors 1-3 of this tile cover objects
+//	bit 6    Y flip
+//	bit 5    X flip
+//	bit 3    tile data VRAM bank
+//	bit 0-2  BG palette
+// Source: https://gbdev.io/pandocs/Tile_Maps.html, https://gbdev.io/pandocs/Window.html
+// -----------------------------
+
+// LCDC bits used here
+const (
+	lcdcBGEnable     = 1 << 0
+	lcdcBGMap        = 1 << 3
+	lcdcTileData     = 1 << 4
+	lcdcWindowEnable = 1 << 5
+	lcdcWindowMap    = 1 << 6
+)
+
+const (
+	tileMapLow   = 0x1800 // 0x9800, as a VRAM offset
+	tileMapHigh  = 0x1C00 // 0x9C00
+	tileBlock2   = 0x1000 // 0x9000, tile 0 with signed addressing
+	bytesPerTile = 16
+)
+
+// tileRow returns the two bitplanes of row y (0-7) of tile number tile in
+// a VRAM bank, using the addressing selected by LCDC bit 4.
+func (p *PPU) tileRow(bank int, tile byte, y int) (lo, hi byte) {
+	vram := p.mmu.VRAM(bank)
+	base := tileBlock2 + int(int8(tile))*bytesPerTile
+	if p.lcdc&lcdcTileData != 0 {
+		base = int(tile) * bytesPerTile
+	}
+	return vram[base+y*2], vram[base+y*2+1]
+}
+
+// mapTile returns the tile number at column x, row y of a tile map, and
+// on CGB its attributes.
+func (p *PPU) mapTile(mapBase, x, y int) (tile, attr byte) {
+	i := mapBase + (y&31)*32 + (x & 31)
+	if p.cgb {
+		attr = p.mmu.VRAM(1)[i]
+	}
+	return p.mmu.VRAM(0)[i], attr
+}
+
+// tileBank returns the VRAM bank holding tile data for an attribute byte
+// (map or OAM). The DMG only has bank 0.
+func (p *PPU) tileBank(attr byte) int {
+	if p.cgb && attr&attrBank != 0 {
+		return 1
+	}
+	return 0
+}
+
+// pixelIndex extracts the color index (0-3) of column x (0-7) from a row.
+func pixelIndex(lo, hi byte, x int) byte {
+	bit := 7 - x
+	return (lo>>bit)&1 | (hi>>bit&1)<<1
+}
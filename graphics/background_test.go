@@ -0,0 +1,138 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/memory"
+)
+
+// solidTile fills tile number n (0x8000 addressing) with color index c.
+func solidTile(mmu *memory.MMU, base uint16, c byte) {
+	var lo, hi byte
+	if c&1 != 0 {
+		lo = 0xFF
+	}
+	if c&2 != 0 {
+		hi = 0xFF
+	}
+	for row := uint16(0); row < 8; row++ {
+		mmu.Poke(base+row*2, lo)
+		mmu.Poke(base+row*2+1, hi)
+	}
+}
+
+// runFrame steps to the start of VBlank, when the frame is complete.
+func runFrame(p *PPU) {
+	p.Step(VisibleLines*DotsPerLine - int(p.LY())*DotsPerLine - p.Dot())
+}
+
+func checkPixels(t *testing.T, p *PPU, want map[[2]int]uint16) {
+	t.Helper()
+	for pos, shade := range want {
+		if got := p.Frame()[pos[1]][pos[0]]; got != shade {
+			t.Errorf("pixel (%d,%d) = %d; want %d", pos[0], pos[1], got, shade)
+		}
+	}
+}
+
+func TestBackground_TileAddressing(t *testing.T) {
+	tests := []struct {
+		name     string
+		lcdc     byte
+		tile     byte
+		tileAddr uint16
+	}{
+		{"unsigned 0x8000", 0x91, 0x01, 0x8010},
+		{"signed 0x8800", 0x81, 0xFF, 0x8FF0},
+		{"signed tile 0", 0x81, 0x00, 0x9000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mmu := newTestPPU()
+			mmu.Write(LCDCAddr, tt.lcdc)
+			mmu.Write(BGPAddr, 0xE4)
+			solidTile(mmu, tt.tileAddr, 3)
+			for i := uint16(0); i < 0x400; i++ {
+				mmu.Poke(0x9800+i, 0x80) // blank 0x8800 in both modes
+			}
+			mmu.Poke(0x9800, tt.tile)
+			runFrame(p)
+			checkPixels(t, p, map[[2]int]uint16{{0, 0}: 3, {7, 7}: 3, {8, 0}: 0, {0, 8}: 0})
+		})
+	}
+}
+
+func TestBackground_Scroll(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(BGPAddr, 0xE4)
+	mmu.Poke(0x8010, 0x80) // tile 1: only column 0 of row 0 set
+	mmu.Poke(0x9801, 0x01) // map (1,0)
+	mmu.Write(SCXAddr, 3)
+	mmu.Write(SCYAddr, 0)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{5, 0}: 1, {4, 0}: 0, {6, 0}: 0})
+
+	// SCY wraps around the 256-pixel map.
+	mmu.Write(SCYAddr, 0xFF)
+	runFrame(p)
+	p.Step(DotsPerFrame - VisibleLines*DotsPerLine)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{5, 1}: 1, {5, 0}: 0})
+}
+
+func TestBackground_Palette(t *testing.T) {
+	p, mmu := newTestPPU()
+	solidTile(mmu, 0x8000, 2)
+	mmu.Write(BGPAddr, 0x1B) // index 2 -> shade 1
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{0, 0}: 1, {159, 143}: 1})
+}
+
+func TestBackground_Disabled(t *testing.T) {
+	p, mmu := newTestPPU()
+	solidTile(mmu, 0x8000, 3)
+	mmu.Write(BGPAddr, 0xE4)
+	mmu.Write(LCDCAddr, 0x90)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{0, 0}: 0, {80, 70}: 0})
+}
+
+func TestWindow_Position(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(BGPAddr, 0xE4)
+	mmu.Write(LCDCAddr, 0xF1) // window on, window map 0x9C00
+	solidTile(mmu, 0x8020, 2)
+	for i := uint16(0); i < 0x400; i++ {
+		mmu.Poke(0x9C00+i, 0x02)
+	}
+	mmu.Write(WYAddr, 10)
+	mmu.Write(WXAddr, 7+20)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{20, 10}: 2, {159, 143}: 2, {19, 10}: 0, {20, 9}: 0})
+}
+
+func TestWindow_LineCounter(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(BGPAddr, 0xE4)
+	solidTile(mmu, 0x8010, 1)
+	solidTile(mmu, 0x8020, 2)
+	mmu.Poke(0x9C00, 0x01) // window row 0: tile 1
+	mmu.Poke(0x9C20, 0x02) // window row 1: tile 2
+	mmu.Write(WYAddr, 0)
+	mmu.Write(WXAddr, 7)
+	mmu.Write(LCDCAddr, 0xF1)
+
+	// Hide the window on lines 4-13: its counter stops at 4.
+	p.Step(4 * DotsPerLine)
+	mmu.Write(LCDCAddr, 0xD1)
+	p.Step(10 * DotsPerLine)
+	mmu.Write(LCDCAddr, 0xF1)
+	runFrame(p)
+
+	checkPixels(t, p, map[[2]int]uint16{
+		{0, 3}:  1, // window line 3
+		{0, 14}: 1, // window line 4, still tile row 0
+		{0, 17}: 1, // window line 7
+		{0, 18}: 2, // window line 8, tile row 1
+	})
+}
@@ -0,0 +1,211 @@
+package graphics
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Display Colors
+// -----------------------------
+// The frame holds what the PPU produced: DMG shades 0-3 or CGB RGB555.
+// Turning that into RGB is up to the screen, and none of the real screens
+// were neutral:
+//
+//	DMG     green tinted STN panel, dark and low contrast
+//	Pocket  grey-green
+//	Light   blue-green electroluminescent backlight
+//	CGB     reflective TFT with a steep gamma curve; each channel bleeds
+//	        into the others, so raw RGB555 values look far more
+//	        saturated on a PC monitor than on the handheld
+//
+// The display settings only change how frames are exported (Image, RGBA),
+// never emulation state, so they can change at any time.
+// Source: https://gbdev.io/pandocs/Palettes.html, https://github.com/libretro/glsl-shaders/tree/master/handheld/shaders/color
+// -----------------------------
+
+// ErrPalette is returned by ParsePalette for text that is neither a known
+// palette name nor four colors.
+var ErrPalette = errors.New("palette must be a name or four #RRGGBB colors")
+
+// Palette maps the four DMG shades to colors, lightest first.
+type Palette [4]color.RGBA
+
+// DMG palettes
+var (
+	// PaletteGray is plain grey levels, the default.
+	PaletteGray = Palette{
+		{0xFF, 0xFF, 0xFF, 0xFF}, {0xAA, 0xAA, 0xAA, 0xFF},
+		{0x55, 0x55, 0x55, 0xFF}, {0x00, 0x00, 0x00, 0xFF},
+	}
+	// PaletteGreen is the classic green DMG screen.
+	PaletteGreen = Palette{
+		{0x9B, 0xBC, 0x0F, 0xFF}, {0x8B, 0xAC, 0x0F, 0xFF},
+		{0x30, 0x62, 0x30, 0xFF}, {0x0F, 0x38, 0x0F, 0xFF},
+	}
+	// PalettePocket is the Game Boy Pocket's grey screen.
+	PalettePocket = Palette{
+		{0xC4, 0xCF, 0xA1, 0xFF}, {0x8B, 0x95, 0x6D, 0xFF},
+		{0x4D, 0x53, 0x3C, 0xFF}, {0x1F, 0x1F, 0x1F, 0xFF},
+	}
+	// PaletteLight is the Game Boy Light with its backlight on.
+	PaletteLight = Palette{
+		{0x00, 0xB5, 0x81, 0xFF}, {0x00, 0x9A, 0x71, 0xFF},
+		{0x00, 0x69, 0x4A, 0xFF}, {0x00, 0x4F, 0x3B, 0xFF},
+	}
+)
+
+// Palettes are the built-in palettes by name, for ParsePalette and
+// configuration files.
+var Palettes = map[string]Palette{
+	"gray":   PaletteGray,
+	"green":  PaletteGreen,
+	"pocket": PalettePocket,
+	"light":  PaletteLight,
+}
+
+// ParsePalette accepts a built-in palette name or four comma-separated
+// colors, lightest first: "#E0F8D0,#88C070,#346856,#081820".
+func ParsePalette(s string) (Palette, error) {
+	if p, ok := Palettes[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return p, nil
+	}
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return Palette{}, fmt.Errorf("%w: %q", ErrPalette, s)
+	}
+	var p Palette
+	for i, f := range fields {
+		hex := strings.TrimPrefix(strings.TrimSpace(f), "#")
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return Palette{}, fmt.Errorf("%w: bad color %q", ErrPalette, f)
+		}
+		p[i] = color.RGBA{byte(v >> 16), byte(v >> 8), byte(v), 0xFF}
+	}
+	return p, nil
+}
+
+// ColorCorrection selects how CGB RGB555 colors are converted to RGB.
+type ColorCorrection int
+
+const (
+	// CorrectionNone expands each channel from 5 to 8 bits, the default.
+	// Colors look as the game's palette data says, not as the CGB showed
+	// them.
+	CorrectionNone ColorCorrection = iota
+
+	// CorrectionCGB simulates the CGB screen: channels are linearised
+	// with the LCD's gamma, dimmed to its peak brightness, mixed into each
+	// other and re-encoded for an sRGB monitor.
+	CorrectionCGB
+
+	// CorrectionGambatte is the cheaper integer channel mix used by the
+	// Gambatte emulator: desaturated but without the gamma curve, so
+	// darker colors stay brighter than CorrectionCGB.
+	CorrectionGambatte
+)
+
+func (c ColorCorrection) String() string {
+	switch c {
+	case CorrectionNone:
+		return "none"
+	case CorrectionCGB:
+		return "cgb"
+	case CorrectionGambatte:
+		return "gambatte"
+	}
+	return fmt.Sprintf("ColorCorrection(%d)", int(c))
+}
+
+// CGB screen model for CorrectionCGB
+const (
+	cgbLCDGamma  = 2.2
+	cgbOutGamma  = 2.2
+	cgbLuminance = 0.94
+)
+
+const (
+	rgb555Colors    = 1 << 15
+	rgb555Mask      = rgb555Colors - 1
+	rgb555ChanShift = 5
+)
+
+// cgbMix is the channel mix of the CGB screen: output channel (row) as a
+// blend of the linear input channels (columns). Rows sum to 1, so greys
+// stay grey.
+var cgbMix = [3][3]float64{
+	{0.82, 0.24, -0.06},
+	{0.125, 0.665, 0.21},
+	{0.195, 0.075, 0.73},
+}
+
+// correctionTable is an RGB555 to 8-bit RGB lookup table.
+type correctionTable [rgb555Colors][3]byte
+
+var correctionTables = map[ColorCorrection]func() *correctionTable{
+	CorrectionNone:     sync.OnceValue(func() *correctionTable { return buildTable(correctNone) }),
+	CorrectionCGB:      sync.OnceValue(func() *correctionTable { return buildTable(correctCGB) }),
+	CorrectionGambatte: sync.OnceValue(func() *correctionTable { return buildTable(correctGambatte) }),
+}
+
+func buildTable(correct func(r, g, b int) (byte, byte, byte)) *correctionTable {
+	t := new(correctionTable)
+	for v := range t {
+		r, g, b := v&0x1F, v>>rgb555ChanShift&0x1F, v>>(2*rgb555ChanShift)&0x1F
+		t[v][0], t[v][1], t[v][2] = correct(r, g, b)
+	}
+	return t
+}
+
+func correctNone(r, g, b int) (byte, byte, byte) {
+	return expand5(uint16(r)), expand5(uint16(g)), expand5(uint16(b))
+}
+
+func correctCGB(r, g, b int) (byte, byte, byte) {
+	var in [3]float64
+	for i, c := range [3]int{r, g, b} {
+		in[i] = math.Pow(float64(c)/31, cgbLCDGamma) * cgbLuminance
+	}
+	var out [3]byte
+	for i, row := range cgbMix {
+		linear := row[0]*in[0] + row[1]*in[1] + row[2]*in[2]
+		linear = min(max(linear, 0), 1)
+		out[i] = byte(math.Round(math.Pow(linear, 1/cgbOutGamma) * 255))
+	}
+	return out[0], out[1], out[2]
+}
+
+func correctGambatte(r, g, b int) (byte, byte, byte) {
+	return byte((r*13 + g*2 + b) >> 1), byte((g*3 + b) << 1), byte((r*3 + g*2 + b*11) >> 1)
+}
+
+// SetPalette sets the colors of the four DMG shades in exported frames.
+func (p *PPU) SetPalette(palette Palette) {
+	p.palette = palette
+}
+
+// SetColorCorrection sets how CGB colors are converted in exported frames.
+// Unknown values fall back to CorrectionNone.
+func (p *PPU) SetColorCorrection(c ColorCorrection) {
+	if correctionTables[c] == nil {
+		c = CorrectionNone
+	}
+	p.correction = c
+}
+
+// pixelRGB converts a frame pixel to RGB with the display settings: a
+// shade through the palette on DMG, RGB555 through the color correction
+// on CGB.
+func (p *PPU) pixelRGB(v uint16) (r, g, b byte) {
+	if !p.cgb {
+		c := p.palette[v&3]
+		return c.R, c.G, c.B
+	}
+	rgb := &correctionTables[p.correction]()[v&rgb555Mask]
+	return rgb[0], rgb[1], rgb[2]
+}
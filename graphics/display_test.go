@@ -0,0 +1,77 @@
+package graphics
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestParsePalette(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Palette
+		err  error
+	}{
+		{"green", PaletteGreen, nil},
+		{" Pocket ", PalettePocket, nil},
+		{"#E0F8D0,#88C070, #346856,081820", Palette{
+			{0xE0, 0xF8, 0xD0, 0xFF}, {0x88, 0xC0, 0x70, 0xFF},
+			{0x34, 0x68, 0x56, 0xFF}, {0x08, 0x18, 0x20, 0xFF},
+		}, nil},
+		{"sepia", Palette{}, ErrPalette},
+		{"#FFFFFF,#AAAAAA,#555555", Palette{}, ErrPalette},
+		{"#FFFFFF,#AAAAAA,#555555,#0000", Palette{}, ErrPalette},
+		{"#FFFFFF,#AAAAAA,#555555,#00000G", Palette{}, ErrPalette},
+	}
+	for _, tt := range tests {
+		got, err := ParsePalette(tt.in)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParsePalette(%q) = %v, %v; want %v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestSetPalette(t *testing.T) {
+	p, _ := newTestPPU()
+	p.frame[0][0], p.frame[0][1] = 0, 3
+	p.SetPalette(PaletteGreen)
+	img := p.Image()
+	if got := img.RGBAAt(0, 0); got != PaletteGreen[0] {
+		t.Errorf("shade 0 = %v; want %v", got, PaletteGreen[0])
+	}
+	if got := img.RGBAAt(1, 0); got != PaletteGreen[3] {
+		t.Errorf("shade 3 = %v; want %v", got, PaletteGreen[3])
+	}
+	if p.frame[0][1] != 3 {
+		t.Errorf("frame pixel changed to %d by export", p.frame[0][1])
+	}
+}
+
+func TestColorCorrection(t *testing.T) {
+	const (
+		white = 0x7FFF
+		red   = 0x001F
+	)
+	tests := []struct {
+		correction ColorCorrection
+		v          uint16
+		want       color.RGBA
+	}{
+		{CorrectionNone, white, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}},
+		{CorrectionNone, red, color.RGBA{0xFF, 0, 0, 0xFF}},
+		{CorrectionCGB, 0, color.RGBA{0, 0, 0, 0xFF}},
+		{CorrectionCGB, white, color.RGBA{0xF8, 0xF8, 0xF8, 0xFF}},
+		{CorrectionCGB, red, color.RGBA{0xE3, 0x60, 0x76, 0xFF}},
+		{CorrectionGambatte, white, color.RGBA{0xF8, 0xF8, 0xF8, 0xFF}},
+		{CorrectionGambatte, red, color.RGBA{0xC9, 0, 0x2E, 0xFF}},
+		{ColorCorrection(99), red, color.RGBA{0xFF, 0, 0, 0xFF}}, // falls back to none
+	}
+	for _, tt := range tests {
+		p, _ := newCGBPPU()
+		p.frame[0][0] = tt.v
+		p.SetColorCorrection(tt.correction)
+		if got := p.Image().RGBAAt(0, 0); got != tt.want {
+			t.Errorf("%s: 0x%04X = %v; want %v", tt.correction, tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package graphics
+
+// Pixel FIFO
+// -----------------------------
+// Mode 3 is not a fixed 172 dots: the PPU shifts one pixel per dot out of
+// a background FIFO, which a fetcher refills 8 pixels at a time, and
+// anything that stalls the fetcher or the shifter lengthens the line.
+//
+//	fetcher step  0-1  read the tile number from the map
+//	              2-3  read the low bitplane
+//	              4-5  read the high bitplane
+//	              6+   push the 8 pixels once the FIFO is empty
+//
+// Each line starts with a 6 dot fetch whose result is thrown away, so the
+// first pixel leaves the FIFO on dot 12 of mode 3 and the last on dot 171.
+// On top of that:
+//
+//	SCX & 7     the first SCX%8 pixels are shifted out and discarded
+//	window      reaching WX-7 clears the FIFO and restarts the fetcher on
+//	            the window map: 6 dots
+//	objects     reaching an object's X stalls the shifter; the background
+//	            fetch in progress is finished first (up to 5 dots), then
+//	            the object row is fetched in 6 dots and merged into the
+//	            object FIFO, filling only its transparent slots
+//
+// Registers are read when the hardware reads them: SCX, SCY and the map
+// and tile data bits of LCDC by the fetcher, BGP, OBP0/1 and the enable
+// bits of LCDC when a pixel is shifted out, so mid-line writes take
+// effect at the pixel they would on hardware.
+//
+// dmg-acid2 and mealybug-tearoom have not been run: the CPU can't execute
+// test ROMs yet, so this timing is only checked by the synthetic scenes in
+// fifo_test.go and sprites_test.go, not against the reference images.
+// Source: https://gbdev.io/pandocs/pixel_fifo.html, https://gbdev.io/pandocs/Rendering.html
+// -----------------------------
+
+const (
+	fetchPush       = 6  // fetcher step that pushes to the FIFO
+	fetchStartup    = -6 // the discarded first fetch of a line
+	objFetchDots    = 6
+	objFetchWaitFor = 5 // background fetcher step an object fetch waits for
+)
+
+// objPixel is one slot of the object FIFO.
+type objPixel struct {
+	color      byte // 0 is transparent
+	palette    byte // OBP0/OBP1 on DMG, palette 0-7 on CGB
+	bgPriority bool
+	index      int // OAM index, for CGB priority
+}
+
+// fetcher is the background/window tile fetcher.
+type fetcher struct {
+	step   int
+	x      int // tiles fetched on this line, or window column
+	window bool
+	tile   byte
+	attr   byte // CGB map attributes, 0 on DMG
+	lo, hi byte
+}
+
+// lineState is the mode 3 state of the current line.
+type lineState struct {
+	fetch fetcher
+
+	bg      [8]byte // color indexes
+	bgAttr  byte    // CGB attributes of the tile in bg
+	bgCount int     // pixels left in bg, taken from the end
+
+	obj     [8]objPixel
+	objHead int
+
+	lx      int // next screen column to draw
+	discard int // pixels still to be dropped for SCX fine scroll
+
+	fetched  [spritesPerLine]bool // objects already merged this line
+	objFetch int                  // dots spent fetching the pending object
+	pending  int                  // index into sprites of the object being fetched, or -1
+
+	windowDrawn bool
+}
+
+// startDrawing resets the FIFO for a new line, at the start of mode 3.
+func (p *PPU) startDrawing() {
+	p.line = lineState{pending: -1}
+	p.line.fetch.step = fetchStartup
+	p.line.discard = int(p.scx & 7)
+	if p.wy == p.ly {
+		p.windowTriggered = true
+	}
+}
+
+// drawDot runs one dot of mode 3.
+func (p *PPU) drawDot() {
+	l := &p.line
+
+	if !l.fetch.window && l.discard == 0 && p.windowStarts() {
+		l.fetch = fetcher{window: true}
+		l.bgCount = 0
+		l.windowDrawn = true
+		if p.wx < 7 && l.lx == 0 {
+			l.discard = 7 - int(p.wx)
+		}
+	}
+
+	if l.pending < 0 && l.discard == 0 {
+		l.pending = p.nextObject()
+	}
+	if l.pending >= 0 {
+		if l.fetch.step < objFetchWaitFor || l.bgCount == 0 {
+			p.fetchStep()
+			if l.fetch.step < objFetchWaitFor || l.bgCount == 0 {
+				return
+			}
+		}
+		l.objFetch++
+		if l.objFetch == objFetchDots {
+			p.mergeObject(&p.sprites[l.pending])
+			l.fetched[l.pending] = true
+			l.pending, l.objFetch = -1, 0
+		}
+		return
+	}
+
+	p.fetchStep()
+	if l.bgCount == 0 {
+		return
+	}
+	color, attr := l.bg[8-l.bgCount], l.bgAttr
+	l.bgCount--
+	if l.discard > 0 {
+		l.discard--
+		return
+	}
+	obj := l.obj[l.objHead]
+	l.obj[l.objHead] = objPixel{}
+	l.objHead = (l.objHead + 1) % 8
+	p.frame[p.ly][l.lx] = p.mix(color, attr, obj)
+	l.lx++
+}
+
+// windowStarts reports whether the window begins at the current column.
+func (p *PPU) windowStarts() bool {
+	if p.lcdc&lcdcWindowEnable == 0 || !p.windowTriggered || p.wx > 166 {
+		return false
+	}
+	return p.line.lx == max(int(p.wx)-7, 0)
+}
+
+// nextObject returns the index in sprites of an object starting at the
+// current column that has not been fetched yet, or -1.
+func (p *PPU) nextObject() int {
+	if p.lcdc&lcdcOBJEnable == 0 {
+		return -1
+	}
+	for i := range p.sprites {
+		s := &p.sprites[i]
+		if p.line.fetched[i] || s.x <= -8 {
+			continue
+		}
+		if max(s.x, 0) == p.line.lx {
+			return i
+		}
+	}
+	return -1
+}
+
+// fetchStep advances the background fetcher by one dot.
+func (p *PPU) fetchStep() {
+	l := &p.line
+	f := &l.fetch
+	switch f.step {
+	case 0:
+		f.tile, f.attr = p.fetchTile()
+	case 2:
+		f.lo, _ = p.tileRow(p.tileBank(f.attr), f.tile, p.fetchRow())
+	case 4:
+		_, f.hi = p.tileRow(p.tileBank(f.attr), f.tile, p.fetchRow())
+	}
+	if f.step < fetchPush {
+		f.step++
+		return
+	}
+	if l.bgCount > 0 {
+		return
+	}
+	for x := range l.bg {
+		px := x
+		if f.attr&attrXFlip != 0 {
+			px = 7 - x
+		}
+		l.bg[x] = pixelIndex(f.lo, f.hi, px)
+	}
+	l.bgAttr = f.attr
+	l.bgCount = 8
+	f.step = 0
+	f.x++
+}
+
+// fetchTile reads the map entry for the fetcher's next tile and, on CGB,
+// its attributes.
+func (p *PPU) fetchTile() (tile, attr byte) {
+	f := &p.line.fetch
+	if f.window {
+		winMap := tileMapLow
+		if p.lcdc&lcdcWindowMap != 0 {
+			winMap = tileMapHigh
+		}
+		return p.mapTile(winMap, f.x, p.windowLine/8)
+	}
+	bgMap := tileMapLow
+	if p.lcdc&lcdcBGMap != 0 {
+		bgMap = tileMapHigh
+	}
+	y := (int(p.ly) + int(p.scy)) & 0xFF
+	return p.mapTile(bgMap, int(p.scx>>3)+f.x, y/8)
+}
+
+// fetchRow returns the row within the tile the fetcher is reading, after
+// the CGB Y flip attribute.
+func (p *PPU) fetchRow() int {
+	row := (int(p.ly) + int(p.scy)) & 7
+	if p.line.fetch.window {
+		row = p.windowLine % 8
+	}
+	if p.line.fetch.attr&attrYFlip != 0 {
+		row = 7 - row
+	}
+	return row
+}
+
+// mergeObject loads the object's row into the object FIFO. Slots already
+// holding an opaque pixel keep it, except that on CGB a lower OAM index
+// wins.
+func (p *PPU) mergeObject(s *sprite) {
+	l := &p.line
+	lo, hi := p.spriteRow(s)
+	skip := max(-s.x, 0) // columns left of the screen edge
+	for col := skip; col < 8; col++ {
+		px := col
+		if s.attr&attrXFlip != 0 {
+			px = 7 - col
+		}
+		color := pixelIndex(lo, hi, px)
+		slot := &l.obj[(l.objHead+col-skip)%8]
+		if color == 0 || slot.color != 0 && !(p.cgb && s.index < slot.index) {
+			continue
+		}
+		*slot = objPixel{color: color, bgPriority: s.attr&attrBGPriority != 0, index: s.index}
+		switch {
+		case p.cgb:
+			slot.palette = s.attr & attrCGBPalette
+		case s.attr&attrDMGPalette != 0:
+			slot.palette = 1
+		}
+	}
+}
+
+// mix picks the background or object pixel and maps it through its
+// palette: a DMG shade, or an RGB555 color on CGB.
+//
+// On DMG, LCDC bit 0 blanks the background and window. On CGB it is the
+// master priority instead: clear, objects are always on top; set, BG
+// colors 1-3 cover objects whose OAM or map attribute bit 7 is set.
+func (p *PPU) mix(bg, attr byte, obj objPixel) uint16 {
+	if p.cgb {
+		bgOnTop := p.lcdc&lcdcBGEnable != 0 && bg != 0 && (obj.bgPriority || attr&attrBGPriority != 0)
+		if obj.color != 0 && p.lcdc&lcdcOBJEnable != 0 && !bgOnTop {
+			return p.objPalettes.color(obj.palette, obj.color)
+		}
+		return p.bgPalettes.color(attr&attrCGBPalette, bg)
+	}
+
+	if p.lcdc&lcdcBGEnable == 0 {
+		bg = 0
+	}
+	if obj.color != 0 && p.lcdc&lcdcOBJEnable != 0 && !(obj.bgPriority && bg != 0) {
+		palette := p.obp0
+		if obj.palette == 1 {
+			palette = p.obp1
+		}
+		return uint16(palette >> (obj.color * 2) & 0x03)
+	}
+	return uint16(p.bgp >> (bg * 2) & 0x03)
+}
@@ -0,0 +1,98 @@
+package graphics
+
+import "testing"
+
+// mode3Length steps through line 0 and returns how many dots mode 3 took.
+func mode3Length(p *PPU) int {
+	p.Step(oamScanDots)
+	n := 0
+	for p.Mode() == Drawing {
+		p.Step(1)
+		n++
+	}
+	return n
+}
+
+func TestFIFO_Mode3Length(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(p *PPU)
+		want  int
+	}{
+		{"plain", func(p *PPU) {}, 172},
+		{"SCX 3", func(p *PPU) { p.mmu.Write(SCXAddr, 3) }, 175},
+		{"SCX 7", func(p *PPU) { p.mmu.Write(SCXAddr, 7) }, 179},
+		{"SCX 8", func(p *PPU) { p.mmu.Write(SCXAddr, 8) }, 172},
+		{"window", func(p *PPU) {
+			p.mmu.Write(WXAddr, 7+80)
+			p.mmu.Write(LCDCAddr, 0x33)
+		}, 178},
+		{"window disabled", func(p *PPU) { p.mmu.Write(WXAddr, 7+80) }, 172},
+		{"object at 0", func(p *PPU) { setSprite(p.mmu, 0, 0, 0, 0, 0) }, 183},
+		{"object at 5", func(p *PPU) { setSprite(p.mmu, 0, 5, 0, 0, 0) }, 178},
+		{"object at 5, SCX 3", func(p *PPU) {
+			p.mmu.Write(SCXAddr, 3)
+			setSprite(p.mmu, 0, 5, 0, 0, 0)
+		}, 175 + 11}, // (x + SCX) % 8 == 0 waits the full 5 dots
+		{"object at 17", func(p *PPU) { setSprite(p.mmu, 0, 17, 0, 0, 0) }, 172 + 10},
+		{"two objects at 0", func(p *PPU) {
+			setSprite(p.mmu, 0, 0, 0, 0, 0)
+			setSprite(p.mmu, 1, 0, 0, 0, 0)
+		}, 183 + 6},
+		{"object left of screen", func(p *PPU) { setSprite(p.mmu, 0, -4, 0, 0, 0) }, 183},
+		{"object right of screen", func(p *PPU) { setSprite(p.mmu, 0, 160, 0, 0, 0) }, 172},
+		{"objects disabled", func(p *PPU) {
+			setSprite(p.mmu, 0, 0, 0, 0, 0)
+			p.mmu.Write(LCDCAddr, 0x11)
+		}, 172},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mmu := newSpritePPU()
+			mmu.Write(LCDCAddr, 0x13) // off while setting up
+			tt.setup(p)
+			mmu.Write(LCDCAddr, mmu.Read(LCDCAddr)|lcdcEnable)
+			if got := mode3Length(p); got != tt.want {
+				t.Errorf("mode 3 took %d dots; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFIFO_MidLinePalette(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(BGPAddr, 0xE4)
+	p.Step(oamScanDots + 12 + 80) // pixels 0-79 are out
+	mmu.Write(BGPAddr, 0xE7)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{79, 0}: 0, {80, 0}: 3, {0, 1}: 3})
+}
+
+func TestFIFO_MidLineSCX(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(BGPAddr, 0xE4)
+	solidTile(mmu, 0x8010, 1)
+	mmu.Poke(0x9800+20, 0x01) // map column 20
+	p.Step(oamScanDots + 12 + 40)
+
+	// The fetcher is on tile 6; coarse scroll moves the next ones over.
+	mmu.Write(SCXAddr, 8*10)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{
+		{39, 0}: 0, {80, 0}: 1, {87, 0}: 1, {88, 0}: 0, // column 10+10
+		{80, 1}: 1, {79, 1}: 0, {88, 1}: 0, // later lines scroll from the start
+	})
+}
+
+func TestFIFO_MidLineBGEnable(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(BGPAddr, 0xE4)
+	solidTile(mmu, 0x8010, 3)
+	for i := uint16(0); i < 0x400; i++ {
+		mmu.Poke(0x9800+i, 0x01)
+	}
+	p.Step(oamScanDots + 12 + 100)
+	mmu.Write(LCDCAddr, 0x90)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{99, 0}: 3, {100, 0}: 0, {0, 1}: 0})
+}
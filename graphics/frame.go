@@ -0,0 +1,23 @@
+package graphics
+
+// Screen size in pixels
+const (
+	ScreenWidth  = 160
+	ScreenHeight = 144
+)
+
+// Frame is one picture as the LCD shows it. On DMG each pixel is a shade
+// from 0 (lightest) to 3 (darkest), after the palette registers. On CGB it
+// is the RGB555 color from palette RAM, uncorrected.
+type Frame [ScreenHeight][ScreenWidth]uint16
+
+// Frame returns the frame being drawn. It is complete from the start of
+// VBlank until line 0 of the next frame is drawn.
+func (p *PPU) Frame() *Frame {
+	return &p.frame
+}
+
+// clearFrame blanks the screen, as the LCD does while turned off.
+func (p *PPU) clearFrame() {
+	p.frame = Frame{}
+}
@@ -0,0 +1,93 @@
+package graphics
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+)
+
+// ErrScale is returned for a screenshot scale factor below 1.
+var ErrScale = errors.New("scale factor must be at least 1")
+
+// RGBA converts the current frame to 8-bit RGBA with the display settings
+// (SetPalette, SetColorCorrection), row by row, 4 bytes per pixel. It
+// reuses dst when it holds ScreenWidth*ScreenHeight*4 bytes and allocates
+// otherwise. Call it from OnVBlank to get every complete frame.
+func (p *PPU) RGBA(dst []byte) []byte {
+	if len(dst) != ScreenWidth*ScreenHeight*4 {
+		dst = make([]byte, ScreenWidth*ScreenHeight*4)
+	}
+	i := 0
+	for y := range p.frame {
+		for _, v := range p.frame[y] {
+			r, g, b := p.pixelRGB(v)
+			dst[i], dst[i+1], dst[i+2], dst[i+3] = r, g, b, 0xFF
+			i += 4
+		}
+	}
+	return dst
+}
+
+// Image returns a copy of the current frame as an image.
+func (p *PPU) Image() *image.RGBA {
+	return &image.RGBA{
+		Pix:    p.RGBA(nil),
+		Stride: ScreenWidth * 4,
+		Rect:   image.Rect(0, 0, ScreenWidth, ScreenHeight),
+	}
+}
+
+// expand5 scales the low 5 bits of v to 8 bits.
+func expand5(v uint16) byte {
+	c := byte(v & 0x1F)
+	return c<<3 | c>>2
+}
+
+// Scale enlarges img by an integer factor, without smoothing.
+func Scale(img image.Image, scale int) (*image.RGBA, error) {
+	if scale < 1 {
+		return nil, fmt.Errorf("%w: %d", ErrScale, scale)
+	}
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := img.At(b.Min.X+x, b.Min.Y+y)
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					out.Set(x*scale+dx, y*scale+dy, c)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// WritePNG encodes img as a PNG, enlarged by scale.
+func WritePNG(w io.Writer, img image.Image, scale int) error {
+	scaled, err := Scale(img, scale)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, scaled)
+}
+
+// SavePNG writes a screenshot to path, enlarged by scale.
+func SavePNG(path string, img image.Image, scale int) error {
+	scaled, err := Scale(img, scale)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, scaled); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,113 @@
+package graphics
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImage_DMGShades(t *testing.T) {
+	p, _ := newTestPPU()
+	for x := 0; x < 4; x++ {
+		p.frame[0][x] = uint16(x)
+	}
+	img := p.Image()
+	for x, want := range []byte{0xFF, 0xAA, 0x55, 0x00} {
+		if got := img.RGBAAt(x, 0); got != (color.RGBA{want, want, want, 0xFF}) {
+			t.Errorf("pixel %d = %v; want gray 0x%X", x, got, want)
+		}
+	}
+}
+
+func TestImage_CGBColors(t *testing.T) {
+	p, _ := newCGBPPU()
+	p.frame[1][2] = 0x7FFF
+	p.frame[1][3] = 0x001F | 0x10<<5 // full red, half green
+	img := p.Image()
+	tests := []struct {
+		x    int
+		want color.RGBA
+	}{
+		{0, color.RGBA{0, 0, 0, 0xFF}},
+		{2, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}},
+		{3, color.RGBA{0xFF, 0x84, 0, 0xFF}},
+	}
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.x, 1); got != tt.want {
+			t.Errorf("pixel (%d,1) = %v; want %v", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestRGBA_ReusesBuffer(t *testing.T) {
+	p, _ := newTestPPU()
+	buf := make([]byte, ScreenWidth*ScreenHeight*4)
+	if got := p.RGBA(buf); &got[0] != &buf[0] {
+		t.Error("RGBA allocated although dst had the right size")
+	}
+	if got := p.RGBA(nil); len(got) != ScreenWidth*ScreenHeight*4 {
+		t.Errorf("len(RGBA(nil)) = %d; want %d", len(got), ScreenWidth*ScreenHeight*4)
+	}
+}
+
+func TestOnVBlank(t *testing.T) {
+	p, _ := newTestPPU()
+	frames := 0
+	p.OnVBlank = func() {
+		frames++
+		if p.LY() != VisibleLines || p.Mode() != VBlank {
+			t.Errorf("OnVBlank at LY %d mode %s", p.LY(), p.Mode())
+		}
+	}
+	p.Step(3 * DotsPerFrame)
+	if frames != 3 {
+		t.Errorf("OnVBlank called %d times in 3 frames; want 3", frames)
+	}
+}
+
+func TestWritePNG_Scale(t *testing.T) {
+	p, _ := newTestPPU()
+	p.frame[0][1] = 3
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, p.Image(), 3); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != ScreenWidth*3 || b.Dy() != ScreenHeight*3 {
+		t.Fatalf("size %dx%d; want %dx%d", b.Dx(), b.Dy(), ScreenWidth*3, ScreenHeight*3)
+	}
+	black := color.RGBAModel.Convert(color.Black)
+	for _, pt := range [][2]int{{3, 0}, {5, 2}} {
+		if got := color.RGBAModel.Convert(img.At(pt[0], pt[1])); got != black {
+			t.Errorf("pixel %v = %v; want black", pt, got)
+		}
+	}
+	if got := color.RGBAModel.Convert(img.At(2, 0)); got == black {
+		t.Errorf("pixel (2,0) is black; want white")
+	}
+}
+
+func TestSavePNG(t *testing.T) {
+	p, _ := newTestPPU()
+	path := filepath.Join(t.TempDir(), "shot.png")
+	if err := SavePNG(path, p.Image(), 1); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("saved file does not decode: %v", err)
+	}
+	if err := SavePNG(path, p.Image(), 0); !errors.Is(err, ErrScale) {
+		t.Errorf("SavePNG scale 0 = %v; want ErrScale", err)
+	}
+}
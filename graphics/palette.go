@@ -0,0 +1,81 @@
+package graphics
+
+// CGB Palettes
+// -----------------------------
+// The CGB has 8 background and 8 object palettes of 4 colors, each color
+// two bytes of little-endian RGB555 (bits 0-4 red, 5-9 green, 10-14 blue),
+// 64 bytes of palette RAM per kind. They are reached through an index
+// register and a data register:
+//
+//	0xFF68 BCPS/BGPI  bit 7 auto-increment, bits 0-5 byte index
+//	0xFF69 BCPD/BGPD  palette RAM byte at the index
+//	0xFF6A OCPS/OBPI  same for objects
+//	0xFF6B OCPD/OBPD
+//
+// Writing the data register advances the index when bit 7 is set, even
+// during mode 3, when the PPU owns palette RAM: then reads return 0xFF
+// and writes are lost. Object color 0 is transparent, so only colors 1-3
+// of object palettes are ever shown.
+//
+// cgb-acid2 has not been run: the CPU can't execute test ROMs yet, so the
+// CGB path is only checked by the synthetic scenes in palette_test.go,
+// not against the reference image.
+// Source: https://gbdev.io/pandocs/Palettes.html#lcd-color-palettes-cgb-only
+// -----------------------------
+
+// CGB palette registers
+const (
+	BCPSAddr = 0xFF68
+	BCPDAddr = 0xFF69
+	OCPSAddr = 0xFF6A
+	OCPDAddr = 0xFF6B
+)
+
+const (
+	paletteRAMSize   = 64
+	paletteIndexMask = 0x3F
+	paletteAutoInc   = 1 << 7
+	paletteUnusedBit = 1 << 6
+)
+
+// paletteRAM is one set of 8 CGB palettes with its index register.
+type paletteRAM struct {
+	data  [paletteRAMSize]byte
+	index byte // BCPS/OCPS, bit 6 always clear
+}
+
+func (r *paletteRAM) readIndex() byte {
+	return r.index | paletteUnusedBit
+}
+
+func (r *paletteRAM) writeIndex(data byte) {
+	r.index = data &^ paletteUnusedBit
+}
+
+func (r *paletteRAM) readData(locked bool) byte {
+	if locked {
+		return 0xFF
+	}
+	return r.data[r.index&paletteIndexMask]
+}
+
+func (r *paletteRAM) writeData(data byte, locked bool) {
+	if !locked {
+		r.data[r.index&paletteIndexMask] = data
+	}
+	if r.index&paletteAutoInc != 0 {
+		r.index = paletteAutoInc | (r.index+1)&paletteIndexMask
+	}
+}
+
+// pokeData stores data at the current index, ignoring the mode 3 lock and
+// without auto-increment.
+func (r *paletteRAM) pokeData(data byte) {
+	r.data[r.index&paletteIndexMask] = data
+}
+
+// color returns color c (0-3) of palette n (0-7) as RGB555.
+func (r *paletteRAM) color(n, c byte) uint16 {
+	i := int(n&7)*8 + int(c)*2
+	return (uint16(r.data[i]) | uint16(r.data[i+1])<<8) & 0x7FFF
+}
@@ -0,0 +1,198 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/memory"
+)
+
+// setCGBColor writes color c of palette n through an index register
+// (BCPSAddr or OCPSAddr) and the data register after it.
+func setCGBColor(mmu *memory.MMU, indexAddr uint16, n, c byte, rgb uint16) {
+	mmu.Write(indexAddr, paletteAutoInc|n*8+c*2)
+	mmu.Write(indexAddr+1, byte(rgb))
+	mmu.Write(indexAddr+1, byte(rgb>>8))
+}
+
+func newCGBPPU() (*PPU, *memory.MMU) {
+	p, mmu := newTestPPU()
+	mmu.SetCGB(true)
+	p.SetCGB(true)
+	return p, mmu
+}
+
+func TestPalette_Registers(t *testing.T) {
+	p, mmu := newCGBPPU()
+	mmu.Write(LCDCAddr, 0x11) // LCD off: palette RAM is free
+
+	mmu.Write(BCPSAddr, 0xBE) // auto-increment, index 0x3E
+	if got := mmu.Read(BCPSAddr); got != 0xFE {
+		t.Errorf("Read(BCPS) = 0x%X; want 0xFE", got)
+	}
+	mmu.Write(BCPDAddr, 0x12)
+	mmu.Write(BCPDAddr, 0x34)
+	mmu.Write(BCPDAddr, 0x56) // wraps to index 0
+	if got := mmu.Read(BCPSAddr); got != 0xC1 {
+		t.Errorf("Read(BCPS) after wrap = 0x%X; want 0xC1", got)
+	}
+	if got := p.bgPalettes.color(7, 3); got != 0x3412 {
+		t.Errorf("BG palette 7 color 3 = 0x%X; want 0x3412", got)
+	}
+	if got := p.bgPalettes.data[0]; got != 0x56 {
+		t.Errorf("BG palette byte 0 = 0x%X; want 0x56", got)
+	}
+
+	// Without auto-increment the index stays put.
+	mmu.Write(OCPSAddr, 0x05)
+	mmu.Write(OCPDAddr, 0xAA)
+	mmu.Write(OCPDAddr, 0xBB)
+	if got := mmu.Read(OCPDAddr); got != 0xBB {
+		t.Errorf("Read(OCPD) = 0x%X; want 0xBB", got)
+	}
+	if got := mmu.Read(OCPSAddr); got != 0x45 {
+		t.Errorf("Read(OCPS) = 0x%X; want 0x45", got)
+	}
+}
+
+func TestPalette_LockedInMode3(t *testing.T) {
+	p, mmu := newCGBPPU()
+	mmu.Write(BCPSAddr, 0x80)
+	p.Step(oamScanDots)
+	mmu.Write(BCPDAddr, 0x77) // lost, but the index still advances
+	if got := mmu.Read(BCPDAddr); got != 0xFF {
+		t.Errorf("Read(BCPD) in mode 3 = 0x%X; want 0xFF", got)
+	}
+	if got := mmu.Read(BCPSAddr); got != 0xC1 {
+		t.Errorf("Read(BCPS) = 0x%X; want 0xC1", got)
+	}
+	if got := p.bgPalettes.data[0]; got != 0 {
+		t.Errorf("BG palette byte 0 = 0x%X; want 0", got)
+	}
+}
+
+func TestPalette_DMGUnmapped(t *testing.T) {
+	_, mmu := newTestPPU()
+	mmu.Write(BCPSAddr, 0x80)
+	for _, addr := range []uint16{BCPSAddr, BCPDAddr, OCPSAddr, OCPDAddr} {
+		if got := mmu.Read(addr); got != 0xFF {
+			t.Errorf("Read(%X) = 0x%X; want 0xFF", addr, got)
+		}
+	}
+}
+
+func TestCGB_BackgroundAttributes(t *testing.T) {
+	p, mmu := newCGBPPU()
+	mmu.Write(LCDCAddr, 0x11)
+	setCGBColor(mmu, BCPSAddr, 0, 1, 0x0001)
+	setCGBColor(mmu, BCPSAddr, 3, 1, 0x0003)
+	setCGBColor(mmu, BCPSAddr, 3, 2, 0x0032)
+
+	// Tile 1 has pixel (0,0) color 1 in bank 0 and color 2 in bank 1.
+	mmu.Poke(0x8010, 0x80)
+	mmu.Write(memory.VBKAddr, 1)
+	mmu.Poke(0x8011, 0x80)
+	mmu.Write(0x9800, 0x03)           // column 0: palette 3
+	mmu.Write(0x9801, 0x03|attrXFlip) // column 1: X flip
+	mmu.Write(0x9802, 0x03|attrYFlip) // column 2: Y flip
+	mmu.Write(0x9803, 0x03|attrBank)  // column 3: tile from bank 1
+	mmu.Write(0x9804, 0x00)           // column 4: palette 0
+	mmu.Write(memory.VBKAddr, 0)
+	for i := uint16(0); i < 5; i++ {
+		mmu.Write(0x9800+i, 0x01)
+	}
+	mmu.Write(LCDCAddr, 0x91)
+	runFrame(p)
+
+	checkPixels(t, p, map[[2]int]uint16{
+		{0, 0}: 0x0003, {1, 0}: 0,
+		{15, 0}: 0x0003, {8, 0}: 0,
+		{16, 7}: 0x0003, {16, 0}: 0,
+		{24, 0}: 0x0032,
+		{32, 0}: 0x0001,
+	})
+}
+
+func TestCGB_ObjectPalettesAndBank(t *testing.T) {
+	p, mmu := newCGBPPU()
+	mmu.Write(LCDCAddr, 0x13)
+	setCGBColor(mmu, OCPSAddr, 5, 3, 0x7C00)
+	setCGBColor(mmu, OCPSAddr, 2, 3, 0x1234)
+	solidTile(mmu, 0x8030, 3) // bank 0
+	mmu.Write(memory.VBKAddr, 1)
+	solidTile(mmu, 0x8030, 3) // bank 1
+	mmu.Write(memory.VBKAddr, 0)
+	setSprite(mmu, 0, 0, 0, 0x03, 5)
+	setSprite(mmu, 1, 20, 0, 0x03, 2|attrBank)
+	for i := 2; i < oamEntries; i++ {
+		setSprite(mmu, i, 0, -16, 0, 0)
+	}
+	mmu.Write(LCDCAddr, 0x93)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{0, 0}: 0x7C00, {20, 0}: 0x1234, {10, 0}: 0})
+}
+
+func TestCGB_Priority(t *testing.T) {
+	tests := []struct {
+		name    string
+		lcdc    byte
+		mapAttr byte
+		objAttr byte
+		want    uint16
+	}{
+		{"object on top", 0x93, 0, 0, 0x0002},
+		{"object BG priority", 0x93, 0, attrBGPriority, 0x0001},
+		{"map BG priority", 0x93, attrBGPriority, 0, 0x0001},
+		{"master priority off", 0x92, attrBGPriority, attrBGPriority, 0x0002},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mmu := newCGBPPU()
+			mmu.Write(LCDCAddr, 0x13)
+			setCGBColor(mmu, BCPSAddr, 0, 1, 0x0001)
+			setCGBColor(mmu, OCPSAddr, 0, 1, 0x0002)
+			solidTile(mmu, 0x8010, 1)
+			for i := uint16(0); i < 0x400; i++ {
+				mmu.Write(0x9800+i, 0x01)
+			}
+			mmu.Write(memory.VBKAddr, 1)
+			mmu.Write(0x9800, tt.mapAttr)
+			mmu.Write(memory.VBKAddr, 0)
+			for i := 0; i < oamEntries; i++ {
+				setSprite(mmu, i, 0, -16, 0, 0)
+			}
+			setSprite(mmu, 0, 0, 0, 0x01, tt.objAttr)
+			mmu.Write(LCDCAddr, tt.lcdc)
+			runFrame(p)
+			checkPixels(t, p, map[[2]int]uint16{{0, 0}: tt.want})
+		})
+	}
+}
+
+func TestPoke_NoSideEffects(t *testing.T) {
+	p, mmu := newCGBPPU()
+	mmu.Write(BCPSAddr, 0x80)
+	mmu.Poke(BCPDAddr, 0x12)
+	if got := mmu.Read(BCPSAddr); got != 0xC0 {
+		t.Errorf("Read(BCPS) after Poke(BCPD) = 0x%X; want 0xC0", got)
+	}
+	if got := p.bgPalettes.data[0]; got != 0x12 {
+		t.Errorf("BG palette byte 0 = 0x%X; want 0x12", got)
+	}
+
+	mmu.Write(STATAddr, 0x20) // OAM scan interrupt
+	p.Step(oamScanDots + 400)
+	p.frame[0][0] = 0x1234
+	clearIF(mmu)
+	ly, mode := p.LY(), p.Mode()
+	mmu.Poke(LCDCAddr, 0x11)
+	mmu.Poke(LCDCAddr, 0x91)
+	if p.LY() != ly || p.Mode() != mode {
+		t.Errorf("Poke(LCDC) moved the PPU to LY %d mode %s; want LY %d mode %s", p.LY(), p.Mode(), ly, mode)
+	}
+	if p.frame[0][0] != 0x1234 {
+		t.Errorf("Poke(LCDC) cleared the frame")
+	}
+	if got := mmu.Read(memory.IFAddr); got != 0 {
+		t.Errorf("IF = 0x%X after Poke(LCDC); want 0", got)
+	}
+}
@@ -0,0 +1,411 @@
+package graphics
+
+import "github.com/leaf/gameboy/memory"
+
+// PPU Timing
+// -----------------------------
+// A frame is 154 lines of 456 dots (one dot per T-cycle at normal speed),
+// 70224 dots in total:
+//
+//	line 0-143    mode 2 OAM scan  80 dots
+//	              mode 3 drawing   172 dots (longer with scroll/window/sprites)
+//	              mode 0 HBlank    the rest of the 456
+//	line 144-153  mode 1 VBlank    456 dots each
+//
+// LY (0xFF44) is the current line and is compared with LYC (0xFF45) all the
+// time; STAT (0xFF41) shows the mode in bits 0-1 and the match in bit 2.
+// Bits 3-6 of STAT select which conditions drive the STAT interrupt line:
+// HBlank, VBlank, OAM scan and LY=LYC. The interrupt fires on the line's
+// rising edge only, so while one condition keeps it high another becoming
+// true does not fire again ("STAT blocking").
+//
+// Turning the LCD off (LCDC bit 7) stops the PPU: LY reads 0, STAT reads
+// mode 0 and VRAM/OAM are free. Turning it back on restarts at line 0.
+// Source: https://gbdev.io/pandocs/STAT.html, https://gbdev.io/pandocs/LCDC.html
+// -----------------------------
+
+// PPU registers
+const (
+	LCDCAddr = 0xFF40
+	STATAddr = 0xFF41
+	SCYAddr  = 0xFF42
+	SCXAddr  = 0xFF43
+	LYAddr   = 0xFF44
+	LYCAddr  = 0xFF45
+	BGPAddr  = 0xFF47
+	OBP0Addr = 0xFF48
+	OBP1Addr = 0xFF49
+	WYAddr   = 0xFF4A
+	WXAddr   = 0xFF4B
+)
+
+// Timing in dots
+const (
+	DotsPerLine   = 456
+	LinesPerFrame = 154
+	DotsPerFrame  = DotsPerLine * LinesPerFrame
+	VisibleLines  = 144
+
+	oamScanDots = 80
+	drawingDots = 172 // shortest mode 3: no fine scroll, window or objects
+)
+
+// LCDC bits
+const (
+	lcdcEnable = 1 << 7
+)
+
+// STAT bits
+const (
+	statLYCMatch   = 1 << 2
+	statHBlankIRQ  = 1 << 3
+	statVBlankIRQ  = 1 << 4
+	statOAMIRQ     = 1 << 5
+	statLYCIRQ     = 1 << 6
+	statWritable   = statHBlankIRQ | statVBlankIRQ | statOAMIRQ | statLYCIRQ
+	statUnusedBits = 1 << 7
+)
+
+// Mode is the PPU mode shown in STAT bits 0-1.
+type Mode byte
+
+const (
+	HBlank Mode = iota
+	VBlank
+	OAMScan
+	Drawing
+)
+
+func (m Mode) String() string {
+	switch m {
+	case HBlank:
+		return "HBlank"
+	case VBlank:
+		return "VBlank"
+	case OAMScan:
+		return "OAM scan"
+	}
+	return "Drawing"
+}
+
+// PPU is the picture processing unit. It owns the LCD registers in the
+// MMU's IO area and is advanced by Step.
+type PPU struct {
+	// OnVBlank is called when a frame is complete, at the start of VBlank.
+	// Frame, Image and RGBA return it until line 0 of the next frame is
+	// drawn. It runs on the emulation goroutine, keep it short.
+	OnVBlank func()
+
+	mmu *memory.MMU
+
+	lcdc, stat byte // stat holds only the writable interrupt select bits
+	scy, scx   byte
+	ly, lyc    byte
+	bgp        byte
+	obp0, obp1 byte
+	wy, wx     byte
+
+	mode Mode
+	dot  int // dot within the current line
+
+	// statLine is the level of the STAT interrupt line, see updateSTAT.
+	statLine bool
+
+	frame Frame
+	line  lineState // pixel FIFO state during mode 3
+
+	// windowTriggered is set once LY has matched WY this frame, windowLine
+	// is the window's own line counter.
+	windowTriggered bool
+	windowLine      int
+
+	// sprites are the objects found by the OAM scan of the current line.
+	sprites []sprite
+
+	// cgb selects CGB rendering: map attributes, palette RAM and priority.
+	cgb         bool
+	bgPalettes  paletteRAM
+	objPalettes paletteRAM
+
+	// Display settings, used only by frame export.
+	palette    Palette
+	correction ColorCorrection
+}
+
+// New returns a PPU in the state the DMG boot ROM leaves it (LCD on, BGP
+// 0xFC) and maps its registers into mmu.
+func New(mmu *memory.MMU) *PPU {
+	p := &PPU{
+		mmu:     mmu,
+		lcdc:    0x91,
+		bgp:     0xFC,
+		sprites: make([]sprite, 0, spritesPerLine),
+		palette: PaletteGray,
+	}
+	for _, addr := range []uint16{
+		LCDCAddr, STATAddr, SCYAddr, SCXAddr, LYAddr, LYCAddr,
+		BGPAddr, OBP0Addr, OBP1Addr, WYAddr, WXAddr,
+		BCPSAddr, BCPDAddr, OCPSAddr, OCPDAddr,
+	} {
+		mmu.MapIO(addr, p)
+	}
+	p.setMode(OAMScan)
+	return p
+}
+
+// SetCGB switches between DMG and CGB rendering rules. The CGB palette
+// registers read 0xFF and ignore writes on DMG.
+func (p *PPU) SetCGB(enabled bool) {
+	p.cgb = enabled
+}
+
+// Mode returns the current mode.
+func (p *PPU) Mode() Mode {
+	return p.mode
+}
+
+// LY returns the current line.
+func (p *PPU) LY() byte {
+	return p.ly
+}
+
+// Dot returns the position within the current line, 0-455.
+func (p *PPU) Dot() int {
+	return p.dot
+}
+
+func (p *PPU) enabled() bool {
+	return p.lcdc&lcdcEnable != 0
+}
+
+// Step advances the PPU by dots dots (T-cycles at normal speed). Mode 3 is
+// run a dot at a time through the pixel FIFO, the other modes jump from
+// one mode change to the next.
+func (p *PPU) Step(dots int) {
+	for dots > 0 && p.enabled() {
+		if p.mode == Drawing {
+			p.drawDot()
+			p.dot++
+			dots--
+			if p.line.lx == ScreenWidth {
+				p.advance()
+			}
+			continue
+		}
+		n := min(dots, p.nextEvent()-p.dot)
+		p.dot += n
+		dots -= n
+		if p.dot == p.nextEvent() {
+			p.advance()
+		}
+	}
+}
+
+// nextEvent returns the dot of the current line at which the mode changes.
+// The end of mode 3 is not known in advance, the FIFO decides it.
+func (p *PPU) nextEvent() int {
+	if p.mode == OAMScan {
+		return oamScanDots
+	}
+	return DotsPerLine
+}
+
+// advance performs the mode change due at the current dot.
+func (p *PPU) advance() {
+	switch p.mode {
+	case OAMScan:
+		p.scanOAM()
+		p.startDrawing()
+		p.setMode(Drawing)
+	case Drawing:
+		if p.line.windowDrawn {
+			p.windowLine++
+		}
+		p.setMode(HBlank)
+	default: // end of an HBlank or VBlank line
+		p.dot = 0
+		p.ly++
+		switch {
+		case p.ly == LinesPerFrame:
+			p.ly = 0
+			p.startFrame()
+			p.setMode(OAMScan)
+		case p.ly == VisibleLines:
+			p.setMode(VBlank)
+			p.mmu.RequestInterrupt(memory.VBlankInterrupt)
+			if p.OnVBlank != nil {
+				p.OnVBlank()
+			}
+		case p.ly < VisibleLines:
+			p.setMode(OAMScan)
+		default:
+			p.updateSTAT()
+		}
+	}
+}
+
+// setMode switches mode, locks or frees VRAM and OAM for the CPU and
+// re-evaluates the STAT line.
+func (p *PPU) setMode(mode Mode) {
+	p.mode = mode
+	p.mmu.SetOAMLocked(mode == OAMScan || mode == Drawing)
+	p.mmu.SetVRAMLocked(mode == Drawing)
+	p.updateSTAT()
+}
+
+// updateSTAT recomputes the STAT interrupt line and requests the interrupt
+// on a rising edge.
+func (p *PPU) updateSTAT() {
+	line := false
+	if p.enabled() {
+		line = p.stat&statLYCIRQ != 0 && p.ly == p.lyc ||
+			p.stat&statHBlankIRQ != 0 && p.mode == HBlank ||
+			p.stat&statVBlankIRQ != 0 && p.mode == VBlank ||
+			p.stat&statOAMIRQ != 0 && p.mode == OAMScan
+	}
+	if line && !p.statLine {
+		p.mmu.RequestInterrupt(memory.LCDStatInterrupt)
+	}
+	p.statLine = line
+}
+
+// startFrame resets the per-frame window state.
+func (p *PPU) startFrame() {
+	p.windowTriggered = false
+	p.windowLine = 0
+}
+
+// setLCDEnabled handles LCDC bit 7 changing.
+func (p *PPU) setLCDEnabled(on bool) {
+	p.ly, p.dot = 0, 0
+	p.startFrame()
+	if on {
+		p.setMode(OAMScan)
+		return
+	}
+	p.clearFrame()
+	p.setMode(HBlank)
+}
+
+func (p *PPU) ReadIO(addr uint16) byte {
+	switch addr {
+	case LCDCAddr:
+		return p.lcdc
+	case STATAddr:
+		stat := statUnusedBits | p.stat
+		if p.ly == p.lyc {
+			stat |= statLYCMatch
+		}
+		if p.enabled() {
+			stat |= byte(p.mode)
+		}
+		return stat
+	case SCYAddr:
+		return p.scy
+	case SCXAddr:
+		return p.scx
+	case LYAddr:
+		return p.ly
+	case LYCAddr:
+		return p.lyc
+	case BGPAddr:
+		return p.bgp
+	case OBP0Addr:
+		return p.obp0
+	case OBP1Addr:
+		return p.obp1
+	case WYAddr:
+		return p.wy
+	case WXAddr:
+		return p.wx
+	}
+	if !p.cgb {
+		return 0xFF
+	}
+	switch addr {
+	case BCPSAddr:
+		return p.bgPalettes.readIndex()
+	case BCPDAddr:
+		return p.bgPalettes.readData(p.paletteLocked())
+	case OCPSAddr:
+		return p.objPalettes.readIndex()
+	case OCPDAddr:
+		return p.objPalettes.readData(p.paletteLocked())
+	}
+	return 0xFF
+}
+
+func (p *PPU) WriteIO(addr uint16, data byte) {
+	switch addr {
+	case LCDCAddr:
+		was := p.enabled()
+		p.lcdc = data
+		if was != p.enabled() {
+			p.setLCDEnabled(!was)
+		}
+	case STATAddr:
+		p.stat = data & statWritable
+		p.updateSTAT()
+	case SCYAddr:
+		p.scy = data
+	case SCXAddr:
+		p.scx = data
+	case LYAddr:
+		// read-only
+	case LYCAddr:
+		p.lyc = data
+		p.updateSTAT()
+	case BGPAddr:
+		p.bgp = data
+	case OBP0Addr:
+		p.obp0 = data
+	case OBP1Addr:
+		p.obp1 = data
+	case WYAddr:
+		p.wy = data
+	case WXAddr:
+		p.wx = data
+	}
+	if !p.cgb {
+		return
+	}
+	switch addr {
+	case BCPSAddr:
+		p.bgPalettes.writeIndex(data)
+	case BCPDAddr:
+		p.bgPalettes.writeData(data, p.paletteLocked())
+	case OCPSAddr:
+		p.objPalettes.writeIndex(data)
+	case OCPDAddr:
+		p.objPalettes.writeData(data, p.paletteLocked())
+	}
+}
+
+// PokeIO stores a register for the debugger. Unlike WriteIO, toggling LCDC
+// bit 7 doesn't reset LY, STAT and LYC don't raise interrupts and palette
+// data writes neither respect the mode 3 lock nor auto-increment.
+func (p *PPU) PokeIO(addr uint16, data byte) {
+	switch addr {
+	case LCDCAddr:
+		p.lcdc = data
+	case STATAddr:
+		p.stat = data & statWritable
+	case LYCAddr:
+		p.lyc = data
+	case BCPDAddr:
+		if p.cgb {
+			p.bgPalettes.pokeData(data)
+		}
+	case OCPDAddr:
+		if p.cgb {
+			p.objPalettes.pokeData(data)
+		}
+	default:
+		p.WriteIO(addr, data)
+	}
+}
+
+// paletteLocked reports whether the PPU is reading palette RAM (mode 3).
+func (p *PPU) paletteLocked() bool {
+	return p.enabled() && p.mode == Drawing
+}
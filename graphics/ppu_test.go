@@ -0,0 +1,188 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/memory"
+)
+
+func newTestPPU() (*PPU, *memory.MMU) {
+	mmu := &memory.MMU{}
+	return New(mmu), mmu
+}
+
+// clearIF acknowledges every pending interrupt.
+func clearIF(mmu *memory.MMU) {
+	mmu.Write(memory.IFAddr, 0x00)
+}
+
+func TestPPU_ModeTiming(t *testing.T) {
+	p, _ := newTestPPU()
+
+	tests := []struct {
+		dots int // total dots since the start of the frame
+		ly   byte
+		mode Mode
+	}{
+		{0, 0, OAMScan},
+		{79, 0, OAMScan},
+		{80, 0, Drawing},
+		{251, 0, Drawing},
+		{252, 0, HBlank},
+		{455, 0, HBlank},
+		{456, 1, OAMScan},
+		{143*DotsPerLine + 300, 143, HBlank},
+		{144 * DotsPerLine, 144, VBlank},
+		{153*DotsPerLine + 455, 153, VBlank},
+		{DotsPerFrame, 0, OAMScan},
+		{DotsPerFrame + 80, 0, Drawing},
+	}
+	done := 0
+	for _, tt := range tests {
+		p.Step(tt.dots - done)
+		done = tt.dots
+		if p.LY() != tt.ly || p.Mode() != tt.mode {
+			t.Errorf("after %d dots: LY %d mode %s; want LY %d mode %s", tt.dots, p.LY(), p.Mode(), tt.ly, tt.mode)
+		}
+	}
+}
+
+func TestPPU_STATRegister(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(STATAddr, 0xFF)
+	mmu.Write(LYCAddr, 0x00)
+	p.Step(oamScanDots)
+
+	// bit 7 always set, mode 3, LY=LYC, all interrupt selects
+	if got := mmu.Read(STATAddr); got != 0xFF {
+		t.Errorf("STAT = 0x%X; want 0xFF", got)
+	}
+	mmu.Write(STATAddr, 0x00)
+	if got := mmu.Read(STATAddr); got != 0x87 {
+		t.Errorf("STAT after writing 0 = 0x%X; want 0x87 (mode and match are read-only)", got)
+	}
+	mmu.Write(LYAddr, 0x42)
+	if got := mmu.Read(LYAddr); got != 0x00 {
+		t.Errorf("LY was written: 0x%X", got)
+	}
+}
+
+func TestPPU_VBlankInterrupt(t *testing.T) {
+	p, mmu := newTestPPU()
+	p.Step(VisibleLines*DotsPerLine - 1)
+	if got := mmu.Read(memory.IFAddr); got&0x01 != 0 {
+		t.Fatalf("VBlank requested early, IF = 0x%X", got)
+	}
+	p.Step(1)
+	if got := mmu.Read(memory.IFAddr); got&0x01 == 0 {
+		t.Errorf("VBlank not requested at line 144, IF = 0x%X", got)
+	}
+}
+
+func TestPPU_LYCInterrupt(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(LYCAddr, 5)
+	mmu.Write(STATAddr, statLYCIRQ)
+
+	p.Step(5*DotsPerLine - 1)
+	if got := mmu.Read(memory.IFAddr); got&0x02 != 0 {
+		t.Fatalf("STAT requested before LY=5, IF = 0x%X", got)
+	}
+	p.Step(1)
+	if got := mmu.Read(memory.IFAddr); got&0x02 == 0 {
+		t.Errorf("STAT not requested at LY=LYC, IF = 0x%X", got)
+	}
+	if got := mmu.Read(STATAddr); got&statLYCMatch == 0 {
+		t.Errorf("STAT match bit clear at LY=LYC: 0x%X", got)
+	}
+
+	// The line stays high for the rest of line 5, no second interrupt.
+	clearIF(mmu)
+	p.Step(300)
+	if got := mmu.Read(memory.IFAddr); got&0x02 != 0 {
+		t.Errorf("STAT requested twice on one line, IF = 0x%X", got)
+	}
+}
+
+func TestPPU_STATBlocking(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Write(LYCAddr, 3)
+
+	// HBlank of line 2 holds the line high into line 3, where LY=LYC takes
+	// over without a low period in between.
+	mmu.Write(STATAddr, statHBlankIRQ|statLYCIRQ)
+	p.Step(2*DotsPerLine + 300)
+	clearIF(mmu)
+	p.Step(DotsPerLine - 300)
+	if got := mmu.Read(memory.IFAddr); got&0x02 != 0 {
+		t.Errorf("blocked LYC interrupt fired, IF = 0x%X", got)
+	}
+
+	// Without the HBlank source the line rises at line 3.
+	p, mmu = newTestPPU()
+	mmu.Write(LYCAddr, 3)
+	mmu.Write(STATAddr, statLYCIRQ)
+	p.Step(2*DotsPerLine + 300)
+	clearIF(mmu)
+	p.Step(DotsPerLine - 300)
+	if got := mmu.Read(memory.IFAddr); got&0x02 == 0 {
+		t.Errorf("LYC interrupt did not fire, IF = 0x%X", got)
+	}
+}
+
+func TestPPU_LCDOff(t *testing.T) {
+	p, mmu := newTestPPU()
+	p.Step(10*DotsPerLine + oamScanDots)
+
+	mmu.Write(LCDCAddr, 0x11)
+	if p.LY() != 0 || mmu.Read(STATAddr)&0x03 != 0 {
+		t.Errorf("LCD off: LY %d, STAT 0x%X; want LY 0 mode 0", p.LY(), mmu.Read(STATAddr))
+	}
+	mmu.Write(0x8000, 0x12)
+	if got := mmu.Read(0x8000); got != 0x12 {
+		t.Errorf("VRAM locked with the LCD off, Read(0x8000) = 0x%X", got)
+	}
+	p.Step(DotsPerFrame)
+	if p.LY() != 0 {
+		t.Errorf("PPU ran with the LCD off, LY = %d", p.LY())
+	}
+
+	mmu.Write(LCDCAddr, 0x91)
+	if p.Mode() != OAMScan || p.LY() != 0 {
+		t.Errorf("LCD on: LY %d mode %s; want LY 0 mode OAM scan", p.LY(), p.Mode())
+	}
+	p.Step(DotsPerLine)
+	if p.LY() != 1 {
+		t.Errorf("LY = %d one line after turning on; want 1", p.LY())
+	}
+}
+
+func TestPPU_VRAMAndOAMLocking(t *testing.T) {
+	p, mmu := newTestPPU()
+	mmu.Poke(0x8000, 0x34)
+	mmu.Poke(0xFE00, 0x56)
+
+	tests := []struct {
+		dots      int
+		vram, oam byte
+	}{
+		{0, 0x34, 0xFF},           // OAM scan
+		{oamScanDots, 0xFF, 0xFF}, // drawing
+		{drawingDots, 0x34, 0x56}, // HBlank
+	}
+	for _, tt := range tests {
+		p.Step(tt.dots)
+		if got := mmu.Read(0x8000); got != tt.vram {
+			t.Errorf("mode %s: Read(0x8000) = 0x%X; want 0x%X", p.Mode(), got, tt.vram)
+		}
+		if got := mmu.Read(0xFE00); got != tt.oam {
+			t.Errorf("mode %s: Read(0xFE00) = 0x%X; want 0x%X", p.Mode(), got, tt.oam)
+		}
+	}
+
+	p.Step(oamScanDots + (DotsPerLine - oamScanDots - drawingDots)) // next line, drawing
+	mmu.Write(0x8000, 0x99)
+	if got := mmu.Peek(0x8000); got != 0x34 {
+		t.Errorf("write during mode 3 reached VRAM: 0x%X", got)
+	}
+}
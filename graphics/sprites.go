@@ -0,0 +1,101 @@
+package graphics
+
+import "sort"
+
+// Objects (Sprites)
+// -----------------------------
+// OAM holds 40 entries of 4 bytes: Y+16, X+8, tile, attributes.
+//
+//	attr bit 7    BG over OBJ: BG colors 1-3 are drawn over the object
+//	     bit 6    Y flip
+//	     bit 5    X flip
+//	     bit 4    DMG palette: OBP0 / OBP1
+//	     bit 3    CGB tile VRAM bank
+//	     bit 0-2  CGB palette 0-7
+//
+// Objects are 8x8, or 8x16 with LCDC bit 2 (the tile number's bit 0 is
+// ignored, the bottom half is the next tile). Tiles always use 0x8000
+// addressing. During OAM scan the first 10 entries in OAM order that cover
+// the line are selected; off-screen X still counts toward the limit.
+// Where objects overlap, the DMG draws the one with the smaller X on top
+// (OAM order breaks ties), the CGB always uses OAM order. Color 0 is
+// transparent, so a lower-priority object shows through it.
+// Source: https://gbdev.io/pandocs/OAM.html
+// -----------------------------
+
+const (
+	lcdcOBJEnable = 1 << 1
+	lcdcOBJSize   = 1 << 2
+
+	oamEntries     = 40
+	spritesPerLine = 10
+
+	attrBGPriority = 1 << 7
+	attrYFlip      = 1 << 6
+	attrXFlip      = 1 << 5
+	attrDMGPalette = 1 << 4
+	attrBank       = 1 << 3
+	attrCGBPalette = 0x07
+)
+
+// sprite is an OAM entry selected for the current line.
+type sprite struct {
+	index int // position in OAM
+	y, x  int // screen position of the top left corner
+	tile  byte
+	attr  byte
+}
+
+// spriteHeight returns 8 or 16 depending on LCDC bit 2.
+func (p *PPU) spriteHeight() int {
+	if p.lcdc&lcdcOBJSize != 0 {
+		return 16
+	}
+	return 8
+}
+
+// scanOAM selects the objects on the current line into p.sprites, already
+// in drawing priority order.
+func (p *PPU) scanOAM() {
+	oam := p.mmu.OAM()
+	height := p.spriteHeight()
+	ly := int(p.ly)
+
+	p.sprites = p.sprites[:0]
+	for i := 0; i < oamEntries && len(p.sprites) < spritesPerLine; i++ {
+		entry := oam[i*4 : i*4+4]
+		y := int(entry[0]) - 16
+		if ly < y || ly >= y+height {
+			continue
+		}
+		p.sprites = append(p.sprites, sprite{
+			index: i,
+			y:     y,
+			x:     int(entry[1]) - 8,
+			tile:  entry[2],
+			attr:  entry[3],
+		})
+	}
+	if !p.cgb {
+		sort.SliceStable(p.sprites, func(a, b int) bool {
+			return p.sprites[a].x < p.sprites[b].x
+		})
+	}
+}
+
+// spriteRow returns the bitplanes of the sprite's row on the current line,
+// with Y flip and 8x16 tile selection applied.
+func (p *PPU) spriteRow(s *sprite) (lo, hi byte) {
+	height := p.spriteHeight()
+	row := int(p.ly) - s.y
+	if s.attr&attrYFlip != 0 {
+		row = height - 1 - row
+	}
+	tile := int(s.tile)
+	if height == 16 {
+		tile &^= 1
+	}
+	addr := tile*bytesPerTile + row*2 // row 8-15 runs into the next tile
+	vram := p.mmu.VRAM(p.tileBank(s.attr))
+	return vram[addr], vram[addr+1]
+}
@@ -0,0 +1,134 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/memory"
+)
+
+// setSprite writes OAM entry i with screen coordinates (x, y).
+func setSprite(mmu *memory.MMU, i int, x, y int, tile, attr byte) {
+	base := uint16(0xFE00 + i*4)
+	mmu.Poke(base, byte(y+16))
+	mmu.Poke(base+1, byte(x+8))
+	mmu.Poke(base+2, tile)
+	mmu.Poke(base+3, attr)
+}
+
+func newSpritePPU() (*PPU, *memory.MMU) {
+	p, mmu := newTestPPU()
+	mmu.Write(LCDCAddr, 0x93) // LCD, BG and OBJ on, 8x8
+	mmu.Write(BGPAddr, 0xE4)
+	mmu.Write(OBP0Addr, 0xE4)
+	mmu.Write(OBP1Addr, 0x1B)
+	solidTile(mmu, 0x8010, 1)
+	solidTile(mmu, 0x8020, 2)
+	solidTile(mmu, 0x8030, 3)
+	for i := 0; i < oamEntries; i++ {
+		setSprite(mmu, i, 0, -16, 0, 0) // all off screen
+	}
+	return p, mmu
+}
+
+func TestSprites_Basic(t *testing.T) {
+	p, mmu := newSpritePPU()
+	setSprite(mmu, 0, 10, 20, 0x03, 0)
+	setSprite(mmu, 1, 30, 20, 0x03, attrDMGPalette)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{
+		{10, 20}: 3, {17, 27}: 3, {18, 20}: 0, {10, 28}: 0, {9, 20}: 0,
+		{30, 20}: 0, // OBP1 0x1B maps index 3 to shade 0
+	})
+}
+
+func TestSprites_Flip(t *testing.T) {
+	p, mmu := newSpritePPU()
+	mmu.Poke(0x8040, 0x80) // tile 4: only pixel (0,0)
+	setSprite(mmu, 0, 0, 0, 0x04, 0)
+	setSprite(mmu, 1, 20, 0, 0x04, attrXFlip)
+	setSprite(mmu, 2, 40, 0, 0x04, attrYFlip)
+	setSprite(mmu, 3, 60, 0, 0x04, attrXFlip|attrYFlip)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{
+		{0, 0}: 1, {7, 0}: 0,
+		{27, 0}: 1, {20, 0}: 0,
+		{40, 7}: 1, {40, 0}: 0,
+		{67, 7}: 1, {60, 0}: 0,
+	})
+}
+
+func TestSprites_TallMode(t *testing.T) {
+	p, mmu := newSpritePPU()
+	mmu.Write(LCDCAddr, 0x97)
+	setSprite(mmu, 0, 0, 0, 0x03, 0)          // tiles 2 and 3, bit 0 ignored
+	setSprite(mmu, 1, 20, 0, 0x02, attrYFlip) // tile 3 on top
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{
+		{0, 0}: 2, {0, 7}: 2, {0, 8}: 3, {0, 15}: 3, {0, 16}: 0,
+		{20, 0}: 3, {20, 15}: 2,
+	})
+}
+
+func TestSprites_BGPriority(t *testing.T) {
+	p, mmu := newSpritePPU()
+	mmu.Poke(0x9800, 0x01) // BG tile (0,0) is color 1, the rest color 0
+	setSprite(mmu, 0, 4, 0, 0x03, attrBGPriority)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{
+		{4, 0}:  1, // behind BG color 1
+		{7, 0}:  1,
+		{8, 0}:  3, // over BG color 0
+		{11, 0}: 3,
+	})
+}
+
+func TestSprites_TenPerLine(t *testing.T) {
+	p, mmu := newSpritePPU()
+	for i := 0; i < 10; i++ {
+		setSprite(mmu, i, -8, 0, 0x03, 0) // X=0: hidden but still counted
+	}
+	setSprite(mmu, 10, 50, 0, 0x03, 0)
+	setSprite(mmu, 11, 50, 8, 0x03, 0) // next row of tiles, free slots
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{50, 0}: 0, {50, 8}: 3})
+}
+
+func TestSprites_Priority(t *testing.T) {
+	tests := []struct {
+		name string
+		cgb  bool
+		want uint16
+	}{
+		{"DMG lower X wins", false, 2},
+		{"CGB OAM order wins", true, 0x001F}, // palette 0 color 1
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, mmu := newSpritePPU()
+			p.SetCGB(tt.cgb)
+			setCGBColor(mmu, OCPSAddr, 0, 1, 0x001F)
+			setCGBColor(mmu, OCPSAddr, 0, 2, 0x03E0)
+			setSprite(mmu, 0, 10, 0, 0x01, 0)
+			setSprite(mmu, 1, 8, 0, 0x02, 0)
+			runFrame(p)
+			checkPixels(t, p, map[[2]int]uint16{{10, 0}: tt.want})
+		})
+	}
+}
+
+func TestSprites_TransparentShowsLower(t *testing.T) {
+	p, mmu := newSpritePPU()
+	mmu.Poke(0x8040, 0x80) // tile 4: only column 0 opaque
+	setSprite(mmu, 0, 0, 0, 0x04, 0)
+	setSprite(mmu, 1, 0, 0, 0x02, 0)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{0, 0}: 1, {1, 0}: 2})
+}
+
+func TestSprites_Disabled(t *testing.T) {
+	p, mmu := newSpritePPU()
+	mmu.Write(LCDCAddr, 0x91)
+	setSprite(mmu, 0, 0, 0, 0x03, 0)
+	runFrame(p)
+	checkPixels(t, p, map[[2]int]uint16{{0, 0}: 0})
+}
@@ -0,0 +1,115 @@
+package memory
+
+// Debugger Access Path
+// -----------------------------
+// Read/Write model what the CPU sees: IO registers can have side effects and
+// the PPU locks VRAM/OAM while it uses them. Debuggers, tracers and memory
+// viewers must not disturb the machine, so Peek/Poke go straight to storage.
+//
+// Banked addresses follow the symbol file convention BB:AAAA, e.g.
+// PeekBank(5, 0x4123) is ROM bank 5 and PeekBank(1, 0x8000) is VRAM bank 1,
+// no matter what is mapped right now. The bank is ignored for regions that
+// are not banked (OAM, IO, HRAM, IE).
+// -----------------------------
+
+// Peek reads addr through the current mapping without side effects.
+func (m *MMU) Peek(addr uint16) byte {
+	return m.PeekBank(m.Bank(addr), addr)
+}
+
+// Poke writes addr through the current mapping without side effects.
+// Pokes into 0x0000-0x7FFF patch ROM instead of hitting the mapper registers.
+func (m *MMU) Poke(addr uint16, data byte) {
+	m.PokeBank(m.Bank(addr), addr, data)
+}
+
+// Bank reports which bank is currently mapped at addr.
+// Unbanked regions always report 0.
+func (m *MMU) Bank(addr uint16) int {
+	switch {
+	case addr <= CartridgeROMEnd, addr >= CartridgeRAMStart && addr <= CartridgeRAMEnd:
+		if cart, ok := m.cartridge.(BankedCartridge); ok {
+			return cart.Bank(addr)
+		}
+		if addr >= 0x4000 && addr <= CartridgeROMEnd {
+			return 1
+		}
+		return 0
+
+	case addr >= VRAMStart && addr <= VRAMEnd:
+		return m.vramBank()
+
+	case addr >= WRAMStart && addr <= EchoRAMEnd:
+		if (addr-WRAMStart)&0x1FFF < 0x1000 {
+			return 0
+		}
+		return m.wramBank()
+	}
+	return 0
+}
+
+// PeekBank reads addr from the given bank without side effects.
+func (m *MMU) PeekBank(bank int, addr uint16) byte {
+	switch {
+	case addr <= CartridgeROMEnd, addr >= CartridgeRAMStart && addr <= CartridgeRAMEnd:
+		if m.cartridge == nil {
+			return 0xFF
+		}
+		if cart, ok := m.cartridge.(BankedCartridge); ok {
+			return cart.PeekBank(bank, addr)
+		}
+		// Plain cartridges have no mapper, so reading has no side effects.
+		return m.cartridge.Read(addr)
+
+	case addr >= VRAMStart && addr <= VRAMEnd:
+		return m.vram[bank&0x01][addr-VRAMStart]
+
+	case addr >= WRAMStart && addr <= EchoRAMEnd:
+		wram, offset := m.wramAt(bank, addr)
+		return wram[offset]
+
+	case addr >= OAMStart && addr <= OAMEnd:
+		return m.oam[addr-OAMStart]
+
+	case addr >= IOStart && addr <= IOEnd:
+		return m.readIO(addr)
+
+	case addr >= HRAMStart && addr <= HRAMEnd:
+		return m.hram[addr-HRAMStart]
+
+	case addr == IEAddr:
+		return m.ie
+	}
+	return 0xFF
+}
+
+// PokeBank writes addr in the given bank without side effects.
+func (m *MMU) PokeBank(bank int, addr uint16, data byte) {
+	switch {
+	case addr <= CartridgeROMEnd, addr >= CartridgeRAMStart && addr <= CartridgeRAMEnd:
+		// Without bank access a Poke would land on the mapper registers,
+		// so plain cartridges are read-only to the debugger.
+		if cart, ok := m.cartridge.(BankedCartridge); ok {
+			cart.PokeBank(bank, addr, data)
+		}
+
+	case addr >= VRAMStart && addr <= VRAMEnd:
+		m.vram[bank&0x01][addr-VRAMStart] = data
+
+	case addr >= WRAMStart && addr <= EchoRAMEnd:
+		wram, offset := m.wramAt(bank, addr)
+		wram[offset] = data
+
+	case addr >= OAMStart && addr <= OAMEnd:
+		m.oam[addr-OAMStart] = data
+
+	case addr >= IOStart && addr <= IOEnd:
+		m.pokeIO(addr, data)
+
+	case addr >= HRAMStart && addr <= HRAMEnd:
+		m.hram[addr-HRAMStart] = data
+
+	case addr == IEAddr:
+		m.ie = data
+	}
+}
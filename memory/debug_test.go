@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+func TestPeekBank_ROM(t *testing.T) {
+	b := &carttest.Builder{ROMSize: 0x01} // 4 banks
+	cart := b.AtBank(1, 0x4123, 0x11).AtBank(3, 0x4123, 0x33).Cartridge()
+	mmu := &MMU{cartridge: cart}
+
+	if got := mmu.Peek(0x4123); got != 0x11 {
+		t.Errorf("Peek(0x4123) = 0x%X; want 0x11 (current bank)", got)
+	}
+	if got := mmu.PeekBank(3, 0x4123); got != 0x33 {
+		t.Errorf("PeekBank(3, 0x4123) = 0x%X; want 0x33", got)
+	}
+	if got := mmu.Bank(0x4123); got != 1 {
+		t.Errorf("Bank(0x4123) = %d; want 1", got)
+	}
+}
+
+func TestPoke_ROMBypassesMapper(t *testing.T) {
+	cart := carttest.Builder{ROMSize: 0x01}.Cartridge()
+	mmu := &MMU{cartridge: cart}
+
+	mmu.Poke(0x2000, 0x03)
+	if cart.Writes != 0 {
+		t.Errorf("Poke reached the mapper registers (%d writes)", cart.Writes)
+	}
+	if bank := cart.Bank(0x4000); bank != 1 {
+		t.Errorf("Poke switched ROM bank to %d; want 1", bank)
+	}
+	if got := cart.ROM[0x2000]; got != 0x03 {
+		t.Errorf("Poke did not patch ROM bank 0, got 0x%X", got)
+	}
+}
+
+func TestPeekBank_VRAM(t *testing.T) {
+	mmu := &MMU{}
+	mmu.SetCGB(true)
+
+	mmu.Write(VBKAddr, 0x01)
+	mmu.Write(0x8010, 0xB1)
+	mmu.Write(VBKAddr, 0x00)
+	mmu.Write(0x8010, 0xB0)
+
+	if got := mmu.Peek(0x8010); got != 0xB0 {
+		t.Errorf("Peek(0x8010) = 0x%X; want 0xB0 (bank 0 mapped)", got)
+	}
+	if got := mmu.PeekBank(1, 0x8010); got != 0xB1 {
+		t.Errorf("PeekBank(1, 0x8010) = 0x%X; want 0xB1", got)
+	}
+
+	mmu.PokeBank(1, 0x8010, 0xC1)
+	mmu.Write(VBKAddr, 0x01)
+	if got := mmu.Read(0x8010); got != 0xC1 {
+		t.Errorf("Read(0x8010) from bank 1 = 0x%X; want 0xC1", got)
+	}
+}
+
+func TestPeekBank_WRAM(t *testing.T) {
+	mmu := &MMU{}
+	mmu.SetCGB(true)
+
+	tests := []struct {
+		name string
+		svbk byte
+		bank int
+	}{
+		{"Bank 0 selects 1", 0x00, 1},
+		{"Bank 2", 0x02, 2},
+		{"Bank 7", 0x07, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mmu.Write(SVBKAddr, tt.svbk)
+			mmu.Write(0xD000, byte(tt.bank))
+
+			if got := mmu.Bank(0xD000); got != tt.bank {
+				t.Errorf("Bank(0xD000) = %d; want %d", got, tt.bank)
+			}
+			if got := mmu.PeekBank(tt.bank, 0xD000); got != byte(tt.bank) {
+				t.Errorf("PeekBank(%d, 0xD000) = 0x%X; want 0x%X", tt.bank, got, tt.bank)
+			}
+			if got := mmu.PeekBank(tt.bank, 0xF000); got != byte(tt.bank) {
+				t.Errorf("PeekBank(%d, 0xF000) (echo) = 0x%X; want 0x%X", tt.bank, got, tt.bank)
+			}
+		})
+	}
+}
+
+func TestPeekPoke_DMGIgnoresBankRegisters(t *testing.T) {
+	mmu := &MMU{}
+
+	mmu.Write(VBKAddr, 0x01)
+	mmu.Write(0x8000, 0x42)
+	if got := mmu.PeekBank(0, 0x8000); got != 0x42 {
+		t.Errorf("DMG write went to VRAM bank 1; PeekBank(0, 0x8000) = 0x%X", got)
+	}
+
+	mmu.Poke(0xFF80, 0x99)
+	if got := mmu.Read(0xFF80); got != 0x99 {
+		t.Errorf("Poke(0xFF80) not visible to Read, got 0x%X", got)
+	}
+}
+
+func TestPoke_BankRegistersRemap(t *testing.T) {
+	mmu := NewMMU(nil)
+	mmu.SetCGB(true)
+	mmu.PokeBank(1, 0x8000, 0x11)
+	mmu.PokeBank(3, 0xD000, 0x33)
+
+	mmu.Poke(VBKAddr, 1)
+	if got := mmu.Read(0x8000); got != 0x11 {
+		t.Errorf("Read(0x8000) after Poke(VBK, 1) = 0x%X; want 0x11", got)
+	}
+	mmu.Poke(SVBKAddr, 3)
+	if got := mmu.Read(0xD000); got != 0x33 {
+		t.Errorf("Read(0xD000) after Poke(SVBK, 3) = 0x%X; want 0x33", got)
+	}
+	if got := mmu.Read(0xF000); got != 0x33 {
+		t.Errorf("Read(0xF000) (echo) after Poke(SVBK, 3) = 0x%X; want 0x33", got)
+	}
+}
@@ -0,0 +1,105 @@
+package memory
+
+// IO Devices and Interrupts
+// -----------------------------
+// Hardware blocks (PPU, timer, APU...) own their IO registers: MapIO routes
+// one address in 0xFF00-0xFF7F to the device instead of the plain io array.
+// Peek and Poke go to the device as well, since the device is the only
+// storage the register has: ReadIO must therefore have no side effects,
+// and PokeIO stores a value without any of the side effects of WriteIO.
+//
+// Devices raise interrupts by setting their bit in IF (0xFF0F); the CPU
+// services them when the matching IE bit is set.
+// Source: https://gbdev.io/pandocs/Interrupts.html
+// -----------------------------
+
+// IFAddr is the interrupt flag register.
+const IFAddr = 0xFF0F
+
+// Interrupt is a bit number in IF and IE.
+type Interrupt uint8
+
+const (
+	VBlankInterrupt Interrupt = iota
+	LCDStatInterrupt
+	TimerInterrupt
+	SerialInterrupt
+	JoypadInterrupt
+)
+
+// IODevice is a hardware block with registers in the IO area.
+type IODevice interface {
+	ReadIO(addr uint16) byte
+	WriteIO(addr uint16, data byte)
+	// PokeIO stores data for the debugger: no interrupts, no auto-increment,
+	// no state machine reset.
+	PokeIO(addr uint16, data byte)
+}
+
+// MapIO makes dev handle reads and writes of the IO register at addr.
+// A nil dev gives the register back to the io array.
+func (m *MMU) MapIO(addr uint16, dev IODevice) {
+	if addr < IOStart || addr > IOEnd {
+		panic("memory: MapIO outside 0xFF00-0xFF7F")
+	}
+	m.ioDevices[addr-IOStart] = dev
+}
+
+// RequestInterrupt sets the interrupt's bit in IF.
+func (m *MMU) RequestInterrupt(i Interrupt) {
+	m.io[IFAddr-IOStart] |= 1 << i
+}
+
+// readIO reads an IO register from its device or the io array.
+func (m *MMU) readIO(addr uint16) byte {
+	if dev := m.ioDevices[addr-IOStart]; dev != nil {
+		return dev.ReadIO(addr)
+	}
+	return m.io[addr-IOStart]
+}
+
+// writeIO writes an IO register to its device or the io array.
+func (m *MMU) writeIO(addr uint16, data byte) {
+	if dev := m.ioDevices[addr-IOStart]; dev != nil {
+		dev.WriteIO(addr, data)
+		return
+	}
+	m.storeIO(addr, data)
+}
+
+// pokeIO is writeIO for the debugger, through the device's PokeIO.
+func (m *MMU) pokeIO(addr uint16, data byte) {
+	if dev := m.ioDevices[addr-IOStart]; dev != nil {
+		dev.PokeIO(addr, data)
+		return
+	}
+	m.storeIO(addr, data)
+}
+
+// storeIO writes the io array. The bank registers remap their pages here,
+// so Write and Poke both keep the page table in step.
+func (m *MMU) storeIO(addr uint16, data byte) {
+	m.io[addr-IOStart] = data
+	switch addr {
+	case VBKAddr:
+		m.mapVRAM()
+	case SVBKAddr:
+		m.mapWRAM()
+	}
+}
+
+// SetVRAMLocked blocks CPU access to VRAM, as the PPU does while drawing
+// (mode 3): reads return 0xFF and writes are dropped. Peek/Poke still work.
+func (m *MMU) SetVRAMLocked(locked bool) {
+	if m.vramLocked == locked {
+		return
+	}
+	m.vramLocked = locked
+	m.mapVRAM()
+}
+
+// SetOAMLocked blocks CPU access to OAM, as the PPU does during OAM scan
+// and drawing (modes 2 and 3).
+func (m *MMU) SetOAMLocked(locked bool) {
+	m.oamLocked = locked
+}
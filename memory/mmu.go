@@ -32,99 +32,198 @@ const (
 	IEAddr = 0xFFFF
 )
 
+// CGB bank select registers
+// Source: https://gbdev.io/pandocs/CGB_Registers.html
+const (
+	VBKAddr  = 0xFF4F // VRAM bank select (bit 0)
+	SVBKAddr = 0xFF70 // WRAM bank select (bits 0-2, 0 behaves as 1)
+)
+
 type Cartridge interface {
 	Read(addr uint16) byte
 	Write(addr uint16, data byte)
 }
 
+// BankedCartridge is the cartridge side of the debugger path.
+// A debugger addresses banked memory the way symbol files do (BB:AAAA), so
+// bank is the ROM bank for 0x0000-0x7FFF and the RAM bank for 0xA000-0xBFFF.
+// None of these methods may touch the mapper registers.
+type BankedCartridge interface {
+	Cartridge
+	// Bank reports which bank is currently mapped at addr.
+	Bank(addr uint16) int
+	PeekBank(bank int, addr uint16) byte
+	PokeBank(bank int, addr uint16, data byte)
+}
+
+// PagedCartridge is implemented by cartridges that can hand the MMU the bytes
+// currently visible in a 256-byte page, so reads skip the Read call entirely.
+// The MMU asks again when a write to 0x0000-0x7FFF, where mappers switch
+// banks, changes what Bank reports for a window (0x0000, 0x4000, 0xA000) or
+// whether ReadPage returns a page for it; any other change needs
+// RemapCartridge.
+type PagedCartridge interface {
+	Cartridge
+	// ReadPage returns the 256 bytes mapped at the page containing addr, or
+	// nil if reads from that page must go through Read (RAM disabled, RTC
+	// registers, nibble RAM...).
+	ReadPage(addr uint16) []byte
+}
+
+// RAMBankedCartridge is implemented by cartridges that can copy out their
+// external RAM a bank at a time, so tools like memory search can walk every
+// bank instead of only the mapped one.
+type RAMBankedCartridge interface {
+	BankedCartridge
+	// RAMSize returns the bytes of RAM behind 0xA000-0xBFFF, all banks
+	// together, or 0 if what is mapped there isn't RAM. Chips smaller than
+	// a bank (MBC2's 512 nibbles, 2KiB) mirror across it.
+	RAMSize() int
+	// PeekRAM copies RAM bank bank into dst, as PeekBank reads it from
+	// 0xA000 up, and returns the number of bytes copied: at most one bank,
+	// or the whole chip if it is smaller.
+	PeekRAM(bank int, dst []byte) int
+}
+
 // MMU (Memory Management Unit)
 // In our emulator, this struct acts as both the "Address Decoder" (routing requests)
 // and the "Storage Container" (holding the actual byte slices for WRAM, VRAM, etc).
 type MMU struct {
 	cartridge Cartridge
-	vram      [8192]byte
-	wram      [8192]byte
+	vram      [2][8192]byte // bank 1 only exists on CGB
+	wram      [8][4096]byte // 0xC000 is always bank 0, 0xD000 is bank 1-7 on CGB
 	oam       [160]byte
 	hram      [127]byte
 
+	// cgb enables the VBK/SVBK bank registers
+	cgb bool
+
 	// interrupt enable register
 	ie byte
 
-	// temp for now
-	io [128]byte
-}
+	// io holds the IO registers no device has claimed, see io.go
+	io        [128]byte
+	ioDevices [128]IODevice
 
-func (m *MMU) Read(addr uint16) byte {
-	switch {
-	case addr <= CartridgeROMEnd:
-		return m.cartridge.Read(addr)
+	// set by the PPU while it owns VRAM (mode 3) and OAM (modes 2-3)
+	vramLocked bool
+	oamLocked  bool
 
-	case addr >= VRAMStart && addr <= VRAMEnd:
-		return m.vram[addr-VRAMStart]
+	// page table, see pages.go
+	readPages     [256][]byte
+	writePages    [256][]byte
+	readHandlers  [256]func(addr uint16) byte
+	writeHandlers [256]func(addr uint16, data byte)
+	cartWindows   [len(cartWindows)]cartWindow // as of the last remap
+}
 
-	case addr >= CartridgeRAMStart && addr <= CartridgeRAMEnd:
-		return m.cartridge.Read(addr)
+// NewMMU returns an MMU with cart inserted and the page table built.
+func NewMMU(cart Cartridge) *MMU {
+	m := &MMU{cartridge: cart}
+	m.initPages()
+	return m
+}
 
-	case addr >= WRAMStart && addr <= WRAMEnd:
-		return m.wram[addr-WRAMStart]
+// Cartridge returns the inserted cartridge.
+func (m *MMU) Cartridge() Cartridge {
+	return m.cartridge
+}
 
-	case addr >= EchoRAMStart && addr <= EchoRAMEnd:
-		// Echo RAM is a mirror of WRAM (0xC000 - 0xDDFF)
-		// We subtract EchoStart (0xE000) to get the offset, effectively mapping
-		// 0xE000 -> 0x0000 (Start of WRAM)
-		return m.wram[addr-EchoRAMStart]
+// SetCartridge swaps the inserted cartridge, e.g. to put a decorator in front
+// of it, and remaps its pages.
+func (m *MMU) SetCartridge(cart Cartridge) {
+	m.cartridge = cart
+	m.initPages()
+}
 
-	case addr >= OAMStart && addr <= OAMEnd:
-		return m.oam[addr-OAMStart]
+// RemapCartridge asks the cartridge for its pages again. Cartridges only need
+// this when what they expose changes without a write to 0x0000-0x7FFF.
+func (m *MMU) RemapCartridge() {
+	m.mapCartridge()
+}
 
-	case addr >= UnusableStart && addr <= UnusableEnd:
-		return 0xFF
+// SetCGB switches the VRAM and WRAM bank registers on or off.
+// On a DMG the registers don't exist, so bank 0 / bank 1 stay mapped.
+func (m *MMU) SetCGB(enabled bool) {
+	m.cgb = enabled
+	m.mapVRAM()
+	m.mapWRAM()
+}
 
-	case addr >= IOStart && addr <= IOEnd:
-		return m.io[addr-IOStart]
+// VRAM returns a VRAM bank for the PPU, which reads tiles directly and is
+// not subject to its own locking.
+func (m *MMU) VRAM(bank int) *[8192]byte {
+	return &m.vram[bank&0x01]
+}
 
-	case addr >= HRAMStart && addr <= HRAMEnd:
-		return m.hram[addr-HRAMStart]
+// OAM returns object attribute memory for the PPU.
+func (m *MMU) OAM() *[160]byte {
+	return &m.oam
+}
 
-	case addr == IEAddr:
-		return m.ie
+// vramBank returns the VRAM bank selected by VBK.
+func (m *MMU) vramBank() int {
+	if !m.cgb {
+		return 0
 	}
-	return 0xFF
+	return int(m.io[VBKAddr-IOStart] & 0x01)
 }
 
-func (m *MMU) Write(addr uint16, data byte) {
-	switch {
-	case addr <= CartridgeROMEnd:
-		m.cartridge.Write(addr, data)
-
-	case addr >= VRAMStart && addr <= VRAMEnd:
-		m.vram[addr-VRAMStart] = data
-
-	case addr >= CartridgeRAMStart && addr <= CartridgeRAMEnd:
-		m.cartridge.Write(addr, data)
-
-	case addr >= WRAMStart && addr <= WRAMEnd:
-		m.wram[addr-WRAMStart] = data
+// wramBank returns the WRAM bank mapped at 0xD000-0xDFFF.
+// SVBK value 0 selects bank 1, just like 1 does.
+func (m *MMU) wramBank() int {
+	if !m.cgb {
+		return 1
+	}
+	bank := int(m.io[SVBKAddr-IOStart] & 0x07)
+	if bank == 0 {
+		bank = 1
+	}
+	return bank
+}
 
-	case addr >= EchoRAMStart && addr <= EchoRAMEnd:
-		// Echo RAM is a mirror of WRAM (0xC000 - 0xDDFF)
-		// We subtract EchoStart (0xE000) to get the offset, effectively mapping
-		// 0xE000 -> 0x0000 (Start of WRAM)
-		m.wram[addr-EchoRAMStart] = data
+// wramAt resolves a WRAM or Echo RAM address against an explicit bank and
+// returns the backing bank plus the offset inside it.
+// 0xC000-0xCFFF is hard-wired to bank 0 so the bank only applies above it,
+// and bank 0 up there behaves as bank 1 (same as SVBK).
+func (m *MMU) wramAt(bank int, addr uint16) (*[4096]byte, uint16) {
+	offset := (addr - WRAMStart) & 0x1FFF
+	if offset < 0x1000 {
+		return &m.wram[0], offset
+	}
+	bank &= 0x07
+	if bank == 0 {
+		bank = 1
+	}
+	return &m.wram[bank], offset - 0x1000
+}
 
-	case addr >= OAMStart && addr <= OAMEnd:
-		m.oam[addr-OAMStart] = data
+// Read decodes addr through the page table: mapped pages are a single slice
+// index, everything else goes to that page's handler.
+func (m *MMU) Read(addr uint16) byte {
+	if page := m.readPages[addr>>8]; page != nil {
+		return page[addr&0xFF]
+	}
+	handler := m.readHandlers[addr>>8]
+	if handler == nil {
+		// zero value MMU, build the table on first access
+		m.initPages()
+		return m.Read(addr)
+	}
+	return handler(addr)
+}
 
-	case addr >= UnusableStart && addr <= UnusableEnd:
+// Write decodes addr through the page table, same as Read.
+func (m *MMU) Write(addr uint16, data byte) {
+	if page := m.writePages[addr>>8]; page != nil {
+		page[addr&0xFF] = data
+		return
+	}
+	handler := m.writeHandlers[addr>>8]
+	if handler == nil {
+		m.initPages()
+		m.Write(addr, data)
 		return
-
-	case addr >= IOStart && addr <= IOEnd:
-		m.io[addr-IOStart] = data
-
-	case addr >= HRAMStart && addr <= HRAMEnd:
-		m.hram[addr-HRAMStart] = data
-
-	case addr == IEAddr:
-		m.ie = data
 	}
+	handler(addr, data)
 }
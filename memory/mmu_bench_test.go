@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+// switchRead is the range-comparison decoder the page table replaced.
+// It is kept here as the baseline for the benchmarks below, and goes
+// through the same IO device dispatch as Read so both do the same work.
+func switchRead(m *MMU, addr uint16) byte {
+	switch {
+	case addr <= CartridgeROMEnd:
+		return m.cartridge.Read(addr)
+
+	case addr >= VRAMStart && addr <= VRAMEnd:
+		return m.vram[m.vramBank()][addr-VRAMStart]
+
+	case addr >= CartridgeRAMStart && addr <= CartridgeRAMEnd:
+		return m.cartridge.Read(addr)
+
+	case addr >= WRAMStart && addr <= EchoRAMEnd:
+		wram, offset := m.wramAt(m.wramBank(), addr)
+		return wram[offset]
+
+	case addr >= OAMStart && addr <= OAMEnd:
+		return m.oam[addr-OAMStart]
+
+	case addr >= UnusableStart && addr <= UnusableEnd:
+		return 0xFF
+
+	case addr >= IOStart && addr <= IOEnd:
+		return m.readIO(addr)
+
+	case addr >= HRAMStart && addr <= HRAMEnd:
+		return m.hram[addr-HRAMStart]
+
+	case addr == IEAddr:
+		return m.ie
+	}
+	return 0xFF
+}
+
+// benchAddrs is a rough instruction-stream mix: mostly ROM fetches, then
+// WRAM/HRAM data, a few VRAM and IO accesses.
+var benchAddrs = func() []uint16 {
+	addrs := make([]uint16, 0, 1024)
+	for i := 0; i < 1024; i++ {
+		switch i % 8 {
+		case 0, 1, 2:
+			addrs = append(addrs, 0x4000+uint16(i*7)%0x4000)
+		case 3:
+			addrs = append(addrs, 0x0150+uint16(i))
+		case 4:
+			addrs = append(addrs, 0xC000+uint16(i*13)%0x2000)
+		case 5:
+			addrs = append(addrs, 0xFF80+uint16(i)%0x7F)
+		case 6:
+			addrs = append(addrs, 0x8000+uint16(i*3)%0x2000)
+		case 7:
+			addrs = append(addrs, 0xFF00+uint16(i)%0x80)
+		}
+	}
+	return addrs
+}()
+
+var benchSink byte
+
+func BenchmarkRead_Switch(b *testing.B) {
+	mmu := &MMU{cartridge: carttest.Builder{ROMSize: 0x01}.Cartridge()}
+	var sum byte
+	for i := 0; i < b.N; i++ {
+		for _, addr := range benchAddrs {
+			sum += switchRead(mmu, addr)
+		}
+	}
+	benchSink = sum
+}
+
+func BenchmarkRead_PageTable(b *testing.B) {
+	mmu := &MMU{cartridge: carttest.Builder{ROMSize: 0x01}.Cartridge()}
+	var sum byte
+	for i := 0; i < b.N; i++ {
+		for _, addr := range benchAddrs {
+			sum += mmu.Read(addr)
+		}
+	}
+	benchSink = sum
+}
+
+func BenchmarkWrite_PageTable(b *testing.B) {
+	mmu := &MMU{}
+	for i := 0; i < b.N; i++ {
+		for _, addr := range benchAddrs {
+			if addr >= VRAMStart && addr < OAMStart {
+				mmu.Write(addr, byte(addr))
+			}
+		}
+	}
+}
+
+// BenchmarkWrite_BankSwitch alternates between rewriting the mapped ROM
+// bank, as games do around every far call, and actually switching.
+func BenchmarkWrite_BankSwitch(b *testing.B) {
+	mmu := NewMMU(carttest.Builder{ROMSize: 0x01}.Cartridge())
+	for i := 0; i < b.N; i++ {
+		mmu.Write(0x2000, 0x01)
+		mmu.Write(0x2000, 0x01)
+		mmu.Write(0x2000, 0x02)
+		mmu.Write(0x2000, 0x01)
+	}
+}
+
+func BenchmarkTakeSnapshot(b *testing.B) {
+	mmu := NewMMU(carttest.Builder{ROMSize: 0x01, RAMSize: 0x04}.Cartridge()) // 128KiB RAM
+	mmu.SetCGB(true)
+	for i := 0; i < b.N; i++ {
+		mmu.TakeSnapshot()
+	}
+}
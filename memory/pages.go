@@ -0,0 +1,206 @@
+package memory
+
+// Page Table
+// -----------------------------
+// The 64KiB address space is split into 256 pages of 256 bytes (addr >> 8).
+// Every page has either a slice pointing straight into the backing array
+// (fast path, one index) or a handler for pages with side effects or mixed
+// contents (cartridge registers, OAM/IO/HRAM at 0xFE00-0xFFFF).
+//
+//	page      read                  write
+//	0x00-0x7F cartridge slice/Read  cartridge Write (bank switch -> remap)
+//	0x80-0x9F vram[VBK]             vram[VBK]       (0xFF / dropped while locked)
+//	0xA0-0xBF cartridge slice/Read  cartridge Write
+//	0xC0-0xCF wram[0]               wram[0]
+//	0xD0-0xDF wram[SVBK]            wram[SVBK]
+//	0xE0-0xFD echo of 0xC0-0xDD     echo of 0xC0-0xDD
+//	0xFE-0xFF readHigh              writeHigh
+//
+// Slices are swapped whenever a bank changes, so Read never has to look at
+// the bank registers. Games write the mapper registers constantly, often
+// with the bank already selected, so a cartridge window is only remapped
+// when its Bank changes or its RAM page comes or goes.
+// -----------------------------
+
+const pageSize = 0x100
+
+// initPages installs the handlers and maps every page.
+// Handlers are method values, so they are only created once per MMU and
+// bank switches just swap slices without allocating.
+func (m *MMU) initPages() {
+	for page := 0; page < 256; page++ {
+		switch {
+		case page <= CartridgeROMEnd>>8:
+			m.readHandlers[page] = m.readCartridge
+			m.writeHandlers[page] = m.writeCartridgeROM
+		case page >= CartridgeRAMStart>>8 && page <= CartridgeRAMEnd>>8:
+			m.readHandlers[page] = m.readCartridge
+			m.writeHandlers[page] = m.writeCartridgeRAM
+		case page >= VRAMStart>>8 && page <= VRAMEnd>>8:
+			// only reached while the PPU has VRAM locked
+			m.readHandlers[page] = m.readLocked
+			m.writeHandlers[page] = m.writeLocked
+		default:
+			m.readHandlers[page] = m.readHigh
+			m.writeHandlers[page] = m.writeHigh
+		}
+	}
+	m.mapCartridge()
+	m.mapVRAM()
+	m.mapWRAM()
+}
+
+// cartWindows are the cartridge address windows that switch as a whole.
+var cartWindows = [...]struct{ start, end uint16 }{
+	{0x0000, 0x3FFF},
+	{0x4000, CartridgeROMEnd},
+	{CartridgeRAMStart, CartridgeRAMEnd},
+}
+
+// cartWindow is what decides the pages of one cartridge window.
+type cartWindow struct {
+	bank  int
+	paged bool // ReadPage returns a slice, e.g. RAM enabled
+}
+
+// cartWindowState returns the current state of window i.
+func (m *MMU) cartWindowState(cart PagedCartridge, i int) cartWindow {
+	start := cartWindows[i].start
+	return cartWindow{bank: m.Bank(start), paged: cart.ReadPage(start) != nil}
+}
+
+// mapCartridge asks the cartridge which ROM/RAM pages can be read directly.
+func (m *MMU) mapCartridge() {
+	for i := range cartWindows {
+		m.mapCartridgeWindow(i)
+	}
+}
+
+// mapCartridgeWindow refreshes the pages of cartridge window i.
+func (m *MMU) mapCartridgeWindow(i int) {
+	cart, _ := m.cartridge.(PagedCartridge)
+	for page := cartWindows[i].start >> 8; page <= cartWindows[i].end>>8; page++ {
+		m.readPages[page] = nil
+		if cart != nil {
+			m.readPages[page] = cart.ReadPage(page << 8)
+		}
+	}
+	if cart != nil {
+		m.cartWindows[i] = m.cartWindowState(cart, i)
+	}
+}
+
+// mapVRAM points the VRAM pages at the bank selected by VBK, or at the
+// locked handlers while the PPU is drawing.
+func (m *MMU) mapVRAM() {
+	bank := &m.vram[m.vramBank()]
+	for page := VRAMStart >> 8; page <= VRAMEnd>>8; page++ {
+		offset := page<<8 - VRAMStart
+		m.readPages[page] = bank[offset : offset+pageSize]
+		if m.vramLocked {
+			m.readPages[page] = nil
+		}
+		m.writePages[page] = m.readPages[page]
+	}
+}
+
+// mapWRAM points the WRAM and Echo RAM pages at bank 0 and the bank selected
+// by SVBK.
+func (m *MMU) mapWRAM() {
+	for page := WRAMStart >> 8; page <= EchoRAMEnd>>8; page++ {
+		addr := uint16(page) << 8
+		m.readPages[page] = m.wramPage(m.wramBank(), addr)
+		m.writePages[page] = m.readPages[page]
+	}
+}
+
+// wramPage returns the 256 bytes of WRAM behind addr in the given bank.
+func (m *MMU) wramPage(bank int, addr uint16) []byte {
+	wram, offset := m.wramAt(bank, addr)
+	return wram[offset : offset+pageSize]
+}
+
+func (m *MMU) readCartridge(addr uint16) byte {
+	if m.cartridge == nil {
+		return 0xFF
+	}
+	return m.cartridge.Read(addr)
+}
+
+// writeCartridgeROM forwards mapper register writes and refreshes the
+// cartridge windows whose mapping changed, since this is the only way a
+// cartridge switches banks.
+func (m *MMU) writeCartridgeROM(addr uint16, data byte) {
+	if m.cartridge == nil {
+		return
+	}
+	m.cartridge.Write(addr, data)
+	cart, ok := m.cartridge.(PagedCartridge)
+	if !ok {
+		return
+	}
+	for i := range cartWindows {
+		if m.cartWindowState(cart, i) != m.cartWindows[i] {
+			m.mapCartridgeWindow(i)
+		}
+	}
+}
+
+func (m *MMU) writeCartridgeRAM(addr uint16, data byte) {
+	if m.cartridge == nil {
+		return
+	}
+	m.cartridge.Write(addr, data)
+}
+
+func (m *MMU) readLocked(addr uint16) byte {
+	return 0xFF
+}
+
+func (m *MMU) writeLocked(addr uint16, data byte) {}
+
+// readHigh handles 0xFE00-0xFFFF, where OAM, the unusable area, IO, HRAM and
+// IE share two pages.
+func (m *MMU) readHigh(addr uint16) byte {
+	switch {
+	case addr >= OAMStart && addr <= OAMEnd:
+		if m.oamLocked {
+			return 0xFF
+		}
+		return m.oam[addr-OAMStart]
+
+	case addr >= UnusableStart && addr <= UnusableEnd:
+		return 0xFF
+
+	case addr >= IOStart && addr <= IOEnd:
+		return m.readIO(addr)
+
+	case addr >= HRAMStart && addr <= HRAMEnd:
+		return m.hram[addr-HRAMStart]
+
+	case addr == IEAddr:
+		return m.ie
+	}
+	return 0xFF
+}
+
+func (m *MMU) writeHigh(addr uint16, data byte) {
+	switch {
+	case addr >= OAMStart && addr <= OAMEnd:
+		if !m.oamLocked {
+			m.oam[addr-OAMStart] = data
+		}
+
+	case addr >= UnusableStart && addr <= UnusableEnd:
+		return
+
+	case addr >= IOStart && addr <= IOEnd:
+		m.writeIO(addr, data)
+
+	case addr >= HRAMStart && addr <= HRAMEnd:
+		m.hram[addr-HRAMStart] = data
+
+	case addr == IEAddr:
+		m.ie = data
+	}
+}
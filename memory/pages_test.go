@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+func TestPages_CartridgeBankSwitch(t *testing.T) {
+	b := &carttest.Builder{ROMSize: 0x01}
+	cart := b.AtBank(1, 0x4042, 0x11).AtBank(2, 0x4042, 0x22).Cartridge()
+	mmu := &MMU{cartridge: cart}
+
+	if got := mmu.Read(0x4042); got != 0x11 {
+		t.Errorf("Read(0x4042) = 0x%X; want 0x11 from bank 1", got)
+	}
+
+	mmu.Write(0x2000, 0x02)
+	if got := mmu.Read(0x4042); got != 0x22 {
+		t.Errorf("after bank switch Read(0x4042) = 0x%X; want 0x22 from bank 2", got)
+	}
+}
+
+func TestPages_UnpagedCartridgeFallsBackToRead(t *testing.T) {
+	b := &carttest.Builder{ROMSize: 0x01}
+	cart := b.AtBank(3, 0x4010, 0x33).Cartridge()
+	// Hide ReadPage so the MMU only sees a BankedCartridge.
+	mmu := &MMU{cartridge: struct{ BankedCartridge }{cart}}
+
+	mmu.Write(0x2000, 0x03)
+	if got := mmu.Read(0x4010); got != 0x33 {
+		t.Errorf("Read(0x4010) = 0x%X; want 0x33", got)
+	}
+	if cart.Writes != 1 {
+		t.Errorf("cartridge saw %d writes; want 1", cart.Writes)
+	}
+}
+
+func TestPages_WRAMBankSwitch(t *testing.T) {
+	mmu := &MMU{}
+	mmu.SetCGB(true)
+
+	mmu.Write(SVBKAddr, 0x02)
+	mmu.Write(0xD123, 0xA2)
+	mmu.Write(SVBKAddr, 0x03)
+	mmu.Write(0xD123, 0xA3)
+
+	if got := mmu.Read(0xD123); got != 0xA3 {
+		t.Errorf("Read(0xD123) in bank 3 = 0x%X; want 0xA3", got)
+	}
+	mmu.Write(SVBKAddr, 0x02)
+	if got := mmu.Read(0xF123); got != 0xA2 {
+		t.Errorf("Read(0xF123) (echo) in bank 2 = 0x%X; want 0xA2", got)
+	}
+}
+
+// countingCart counts the ReadPage calls the MMU makes.
+type countingCart struct {
+	*carttest.Cartridge
+	pages int
+}
+
+func (c *countingCart) ReadPage(addr uint16) []byte {
+	c.pages++
+	return c.Cartridge.ReadPage(addr)
+}
+
+func TestPages_RemapOnlyChangedWindow(t *testing.T) {
+	b := &carttest.Builder{ROMSize: 0x01}
+	cart := &countingCart{Cartridge: b.AtBank(2, 0x7FFF, 0x22).Cartridge()}
+	mmu := NewMMU(cart)
+
+	cart.pages = 0
+	mmu.Write(0x2000, 0x01) // bank 1 is already mapped
+	if cart.pages > len(cartWindows) {
+		t.Errorf("rewriting the same bank made %d ReadPage calls; want at most %d", cart.pages, len(cartWindows))
+	}
+
+	cart.pages = 0
+	mmu.Write(0x2000, 0x02)
+	if got := mmu.Read(0x7FFF); got != 0x22 {
+		t.Errorf("Read(0x7FFF) = 0x%X; want 0x22 from bank 2", got)
+	}
+	if limit := 2*len(cartWindows) + 0x40; cart.pages > limit {
+		t.Errorf("switching the ROM bank made %d ReadPage calls; want at most %d", cart.pages, limit)
+	}
+}
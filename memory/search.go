@@ -0,0 +1,309 @@
+package memory
+
+// Memory Search
+// -----------------------------
+// Works like the cheat search in most emulators:
+//   1. NewSearch takes a first snapshot, every address is a candidate.
+//   2. Play a bit, take another Snapshot.
+//   3. Filter keeps the candidates whose value passes the comparison
+//      (against a number, or against the previous snapshot).
+//   4. Repeat until only a handful of addresses are left.
+//
+// Only WRAM, cartridge RAM and HRAM are searched, that's where game state
+// lives. Every bank is searched, not just the mapped one, and candidates
+// are bank:address pairs, so a bank switch between snapshots never
+// compares one bank against another.
+//
+// Snapshot is the only call that touches the MMU: it is a flat copy
+// through the debugger path, so it is safe to take between frames on the
+// emulation goroutine. Filter and Candidates only look at snapshots and
+// can run on another goroutine while the game keeps going.
+// -----------------------------
+
+// ValueSize is the width of the searched value.
+type ValueSize int
+
+const (
+	Size8  ValueSize = 1
+	Size16 ValueSize = 2 // little-endian, like the CPU's 16-bit loads
+)
+
+// Encoding says how the bytes at a candidate are interpreted.
+type Encoding int
+
+const (
+	Unsigned Encoding = iota
+	Signed
+	BCD // two decimal digits per byte, invalid digits never match
+)
+
+// Compare is the test Filter applies to each candidate.
+type Compare int
+
+const (
+	Equal     Compare = iota // current value == the given value
+	NotEqual                 // current value != the given value
+	Changed                  // current value != previous value
+	Unchanged                // current value == previous value
+	Increased                // current value > previous value
+	Decreased                // current value < previous value
+)
+
+// searchRange is an inclusive address range covered by a search. A
+// candidate never spans two ranges, since the bank behind each can differ.
+type searchRange struct {
+	start, end uint16
+}
+
+const wramBankStart = WRAMStart + 0x1000 // 0xD000, the switchable half
+
+var searchRanges = []searchRange{
+	{CartridgeRAMStart, CartridgeRAMEnd},
+	{WRAMStart, wramBankStart - 1},
+	{wramBankStart, WRAMEnd},
+	{HRAMStart, HRAMEnd},
+}
+
+const cartRAMBankSize = CartridgeRAMEnd - CartridgeRAMStart + 1
+
+// Snapshot is a copy of the searchable memory at one point in time, every
+// bank included.
+type Snapshot struct {
+	cartRAM   map[int][]byte // by bank, shorter than a bank for small chips
+	cartBanks []int
+	wram      [8][0x1000]byte
+	cgb       bool
+	hram      [HRAMEnd - HRAMStart + 1]byte
+}
+
+// TakeSnapshot copies HRAM, every WRAM bank and every cartridge RAM bank,
+// up to the size of the RAM chip. Cartridges that don't implement
+// RAMBankedCartridge only have their mapped RAM bank copied.
+func (m *MMU) TakeSnapshot() *Snapshot {
+	snap := &Snapshot{wram: m.wram, cgb: m.cgb, hram: m.hram, cartRAM: map[int][]byte{}}
+	switch cart := m.cartridge.(type) {
+	case nil:
+	case RAMBankedCartridge:
+		size := cart.RAMSize()
+		buf := make([]byte, size)
+		for bank := 0; bank*cartRAMBankSize < size; bank++ {
+			ram := buf[bank*cartRAMBankSize : min(size, (bank+1)*cartRAMBankSize)]
+			snap.addCartBank(bank, ram[:cart.PeekRAM(bank, ram)])
+		}
+	default:
+		bank := m.Bank(CartridgeRAMStart)
+		ram := make([]byte, cartRAMBankSize)
+		for i := range ram {
+			ram[i] = m.PeekBank(bank, CartridgeRAMStart+uint16(i))
+		}
+		snap.addCartBank(bank, ram)
+	}
+	return snap
+}
+
+func (s *Snapshot) addCartBank(bank int, ram []byte) {
+	s.cartBanks = append(s.cartBanks, bank)
+	s.cartRAM[bank] = ram
+}
+
+// banks returns the banks a snapshot holds for a search range.
+func (s *Snapshot) banks(r searchRange) []int {
+	switch r.start {
+	case CartridgeRAMStart:
+		return s.cartBanks
+	case wramBankStart:
+		if s.cgb {
+			return []int{1, 2, 3, 4, 5, 6, 7}
+		}
+		return []int{1}
+	}
+	return []int{0}
+}
+
+// end returns the last address of r held for bank: cartridge RAM chips can
+// be smaller than the window.
+func (s *Snapshot) end(r searchRange, bank int) uint16 {
+	if r.start == CartridgeRAMStart {
+		return r.start + uint16(len(s.cartRAM[bank])) - 1
+	}
+	return r.end
+}
+
+// At returns the byte at bank:addr in the snapshot, ok is false outside
+// the searched regions and banks. The bank is ignored where memory is not
+// banked (0xC000-0xCFFF, HRAM).
+func (s *Snapshot) At(bank int, addr uint16) (value byte, ok bool) {
+	switch {
+	case addr >= CartridgeRAMStart && addr <= CartridgeRAMEnd:
+		ram := s.cartRAM[bank]
+		if int(addr-CartridgeRAMStart) >= len(ram) {
+			return 0, false
+		}
+		return ram[addr-CartridgeRAMStart], true
+	case addr >= WRAMStart && addr < wramBankStart:
+		return s.wram[0][addr-WRAMStart], true
+	case addr >= wramBankStart && addr <= WRAMEnd:
+		if bank < 1 || bank > 7 || !s.cgb && bank != 1 {
+			return 0, false
+		}
+		return s.wram[bank][addr-wramBankStart], true
+	case addr >= HRAMStart && addr <= HRAMEnd:
+		return s.hram[addr-HRAMStart], true
+	}
+	return 0, false
+}
+
+// Candidate is an address still matching every filter so far. Bank follows
+// the debugger convention BB:AAAA and is 0 for unbanked memory.
+type Candidate struct {
+	Bank  int
+	Addr  uint16
+	Value int // decoded value in the latest snapshot
+}
+
+// location is a banked address.
+type location struct {
+	bank int
+	addr uint16
+}
+
+// Search narrows down candidate addresses across successive snapshots.
+type Search struct {
+	mmu        *MMU
+	size       ValueSize
+	encoding   Encoding
+	prev       *Snapshot
+	candidates []location
+}
+
+// NewSearch starts a search with every address of every bank in the
+// searched regions as a candidate. A 16-bit value must fit entirely in one
+// region.
+func NewSearch(m *MMU, size ValueSize, encoding Encoding) *Search {
+	s := &Search{mmu: m, size: size, encoding: encoding, prev: m.TakeSnapshot()}
+	for _, r := range searchRanges {
+		for _, bank := range s.prev.banks(r) {
+			end := uint32(s.prev.end(r, bank))
+			for addr := uint32(r.start); addr+uint32(size)-1 <= end; addr++ {
+				s.candidates = append(s.candidates, location{bank, uint16(addr)})
+			}
+		}
+	}
+	return s
+}
+
+// Snapshot takes a new snapshot of the MMU. Call it on the emulation
+// goroutine, then hand the result to Filter.
+func (s *Search) Snapshot() *Snapshot {
+	return s.mmu.TakeSnapshot()
+}
+
+// Filter keeps only the candidates that pass cmp in snap and makes snap the
+// new reference for Changed/Increased/Decreased. value is only used by
+// Equal and NotEqual. It returns how many candidates are left.
+func (s *Search) Filter(snap *Snapshot, cmp Compare, value int) int {
+	kept := s.candidates[:0]
+	for _, loc := range s.candidates {
+		cur, ok := s.decode(snap, loc)
+		if !ok {
+			continue
+		}
+		prev, prevOK := s.decode(s.prev, loc)
+		if s.match(cmp, cur, prev, prevOK, value) {
+			kept = append(kept, loc)
+		}
+	}
+	s.candidates = kept
+	s.prev = snap
+	return len(kept)
+}
+
+// Candidates returns the remaining addresses with their latest values.
+func (s *Search) Candidates() []Candidate {
+	out := make([]Candidate, 0, len(s.candidates))
+	for _, loc := range s.candidates {
+		value, _ := s.decode(s.prev, loc)
+		out = append(out, Candidate{Bank: loc.bank, Addr: loc.addr, Value: value})
+	}
+	return out
+}
+
+// Len returns how many candidates are left.
+func (s *Search) Len() int {
+	return len(s.candidates)
+}
+
+func (s *Search) match(cmp Compare, cur, prev int, prevOK bool, value int) bool {
+	switch cmp {
+	case Equal:
+		return cur == value
+	case NotEqual:
+		return cur != value
+	}
+	// Relative comparisons need a decodable previous value (BCD may not be).
+	if !prevOK {
+		return false
+	}
+	switch cmp {
+	case Changed:
+		return cur != prev
+	case Unchanged:
+		return cur == prev
+	case Increased:
+		return cur > prev
+	case Decreased:
+		return cur < prev
+	}
+	return false
+}
+
+// decode reads the candidate at loc from snap using the search's size and
+// encoding.
+func (s *Search) decode(snap *Snapshot, loc location) (int, bool) {
+	lo, ok := snap.At(loc.bank, loc.addr)
+	if !ok {
+		return 0, false
+	}
+	var hi byte
+	if s.size == Size16 {
+		if hi, ok = snap.At(loc.bank, loc.addr+1); !ok {
+			return 0, false
+		}
+	}
+
+	switch s.encoding {
+	case Signed:
+		if s.size == Size16 {
+			return int(int16(uint16(hi)<<8 | uint16(lo))), true
+		}
+		return int(int8(lo)), true
+
+	case BCD:
+		low, ok := decodeBCD(lo)
+		if !ok {
+			return 0, false
+		}
+		if s.size == Size8 {
+			return low, true
+		}
+		high, ok := decodeBCD(hi)
+		if !ok {
+			return 0, false
+		}
+		return high*100 + low, true
+	}
+
+	if s.size == Size16 {
+		return int(uint16(hi)<<8 | uint16(lo)), true
+	}
+	return int(lo), true
+}
+
+// decodeBCD turns 0x42 into 42, rejecting bytes with a nibble above 9.
+func decodeBCD(b byte) (int, bool) {
+	hi, lo := b>>4, b&0x0F
+	if hi > 9 || lo > 9 {
+		return 0, false
+	}
+	return int(hi)*10 + int(lo), true
+}
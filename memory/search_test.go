@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/leaf/gameboy/cartridge/carttest"
+)
+
+func TestSearch_NarrowsDownCounter(t *testing.T) {
+	mmu := &MMU{}
+	lives := uint16(0xC0A0)
+	mmu.Write(lives, 3)
+	mmu.Write(0xC0A1, 3) // decoy that never changes
+
+	search := NewSearch(mmu, Size8, Unsigned)
+
+	mmu.Write(lives, 2)
+	if n := search.Filter(search.Snapshot(), Decreased, 0); n == 0 {
+		t.Fatalf("Decreased removed every candidate")
+	}
+	mmu.Write(lives, 5)
+	search.Filter(search.Snapshot(), Increased, 0)
+	search.Filter(search.Snapshot(), Equal, 5)
+
+	got := search.Candidates()
+	if len(got) != 1 || got[0].Addr != lives || got[0].Value != 5 {
+		t.Errorf("Candidates() = %+v; want only 0x%X = 5", got, lives)
+	}
+}
+
+func TestSearch_Encodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     ValueSize
+		encoding Encoding
+		lo, hi   byte
+		want     int
+	}{
+		{"Unsigned 8", Size8, Unsigned, 0xFE, 0x00, 254},
+		{"Signed 8", Size8, Signed, 0xFE, 0x00, -2},
+		{"Unsigned 16", Size16, Unsigned, 0x34, 0x12, 0x1234},
+		{"Signed 16", Size16, Signed, 0xFF, 0xFF, -1},
+		{"BCD 8", Size8, BCD, 0x42, 0x00, 42},
+		{"BCD 16", Size16, BCD, 0x99, 0x12, 1299},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mmu := &MMU{}
+			mmu.Write(0xFF90, tt.lo)
+			mmu.Write(0xFF91, tt.hi)
+
+			search := NewSearch(mmu, tt.size, tt.encoding)
+			search.Filter(search.Snapshot(), Equal, tt.want)
+
+			found := false
+			for _, c := range search.Candidates() {
+				if c.Addr == 0xFF90 {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("0xFF90 did not match %d", tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch_InvalidBCDNeverMatches(t *testing.T) {
+	mmu := &MMU{}
+	mmu.Write(0xC000, 0x1A)
+
+	search := NewSearch(mmu, Size8, BCD)
+	search.Filter(search.Snapshot(), Unchanged, 0)
+	for _, c := range search.Candidates() {
+		if c.Addr == 0xC000 {
+			t.Errorf("0xC000 holds 0x1A, which is not BCD, but is still a candidate")
+		}
+	}
+}
+
+func TestSearch_16BitStaysInRegion(t *testing.T) {
+	mmu := &MMU{}
+	search := NewSearch(mmu, Size16, Unsigned)
+	for _, c := range search.Candidates() {
+		switch c.Addr {
+		case wramBankStart - 1, WRAMEnd, HRAMEnd, CartridgeRAMEnd:
+			t.Errorf("16-bit candidate 0x%X runs past the end of its region", c.Addr)
+		}
+	}
+}
+
+func TestSearch_WRAMBankSwitch(t *testing.T) {
+	mmu := NewMMU(nil)
+	mmu.SetCGB(true)
+	mmu.Write(SVBKAddr, 2)
+	mmu.Write(0xD010, 0x10)
+	mmu.Write(SVBKAddr, 3)
+	mmu.Write(0xD010, 0x20)
+	mmu.Write(SVBKAddr, 2)
+
+	search := NewSearch(mmu, Size8, Unsigned)
+	mmu.Write(SVBKAddr, 3) // nothing changed, only the mapping
+	search.Filter(search.Snapshot(), Increased, 0)
+	for _, c := range search.Candidates() {
+		if c.Addr == 0xD010 {
+			t.Errorf("%02X:D010 reported Increased after a bank switch", c.Bank)
+		}
+	}
+
+	search = NewSearch(mmu, Size8, Unsigned)
+	search.Filter(search.Snapshot(), Equal, 0x10)
+	found := false
+	for _, c := range search.Candidates() {
+		if c.Bank == 2 && c.Addr == 0xD010 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("02:D010 = 0x10 not found while bank 3 is mapped")
+	}
+}
+
+func TestSearch_UnmappedCartRAMBank(t *testing.T) {
+	cart := carttest.Builder{ROMSize: 0x01, RAMSize: 0x03}.Cartridge() // 4 banks
+	mmu := NewMMU(cart)
+	cart.PokeBank(2, 0xA123, 0x77)
+
+	search := NewSearch(mmu, Size8, Unsigned)
+	search.Filter(search.Snapshot(), Equal, 0x77)
+	got := search.Candidates()
+	if len(got) != 1 || got[0].Bank != 2 || got[0].Addr != 0xA123 {
+		t.Errorf("Candidates() = %+v; want only 02:A123", got)
+	}
+}
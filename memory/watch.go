@@ -0,0 +1,176 @@
+package memory
+
+// Bus is everything a bus decorator needs from the MMU.
+// It matches cpu.MemoryBus so a decorator can sit between the two.
+type Bus interface {
+	Read(addr uint16) byte
+	Write(addr uint16, data byte)
+	Peek(addr uint16) byte
+	Poke(addr uint16, data byte)
+}
+
+// AccessType says which kind of access a watchpoint traps. Types can be
+// combined, e.g. WatchRead|WatchWrite.
+type AccessType byte
+
+const (
+	WatchRead   AccessType = 1 << iota // any read in range
+	WatchWrite                         // any write in range, even if the value doesn't change
+	WatchChange                        // a write that actually changed the stored value
+)
+
+func (a AccessType) String() string {
+	switch a {
+	case WatchRead:
+		return "read"
+	case WatchWrite:
+		return "write"
+	case WatchChange:
+		return "change"
+	}
+	return "access"
+}
+
+// Watchpoint traps accesses to the inclusive address range Start-End.
+type Watchpoint struct {
+	ID    int
+	Start uint16
+	End   uint16
+	Type  AccessType
+}
+
+// WatchHit describes the access that triggered a watchpoint.
+// For reads Old and New are both the value read.
+type WatchHit struct {
+	Watchpoint Watchpoint
+	PC         uint16 // address of the instruction doing the access
+	Addr       uint16
+	Access     AccessType
+	Old        byte
+	New        byte
+}
+
+// WatchBus is a MemoryBus decorator that checks every CPU access against a
+// list of watchpoints. The access always completes (like a hardware data
+// breakpoint), then the bus reports Halted until Resume is called so the run
+// loop can stop before the next instruction:
+//
+//	for !watch.Halted() {
+//		cpu.RunNextInstruction()
+//	}
+//
+// Peek and Poke pass straight through so a debugger inspecting memory never
+// triggers its own watchpoints.
+type WatchBus struct {
+	bus         Bus
+	pc          func() uint16
+	watchpoints []Watchpoint
+	nextID      int
+
+	hit    WatchHit
+	halted bool
+
+	// OnHit, if set, is called for every triggered watchpoint.
+	OnHit func(WatchHit)
+}
+
+// NewWatchBus wraps bus. pc reports the address of the instruction currently
+// executing (usually cpu.InstructionPC); it may be nil.
+func NewWatchBus(bus Bus, pc func() uint16) *WatchBus {
+	return &WatchBus{bus: bus, pc: pc, nextID: 1}
+}
+
+// Add installs a watchpoint on start-end (inclusive) and returns its ID.
+func (w *WatchBus) Add(start, end uint16, typ AccessType) int {
+	if end < start {
+		start, end = end, start
+	}
+	id := w.nextID
+	w.nextID++
+	w.watchpoints = append(w.watchpoints, Watchpoint{ID: id, Start: start, End: end, Type: typ})
+	return id
+}
+
+// Remove deletes the watchpoint with the given ID, reporting whether it
+// existed.
+func (w *WatchBus) Remove(id int) bool {
+	for i, wp := range w.watchpoints {
+		if wp.ID == id {
+			w.watchpoints = append(w.watchpoints[:i], w.watchpoints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Watchpoints returns a copy of the installed watchpoints.
+func (w *WatchBus) Watchpoints() []Watchpoint {
+	return append([]Watchpoint(nil), w.watchpoints...)
+}
+
+// Halted reports whether a watchpoint fired since the last Resume.
+func (w *WatchBus) Halted() bool {
+	return w.halted
+}
+
+// LastHit returns the first hit since the last Resume.
+func (w *WatchBus) LastHit() (WatchHit, bool) {
+	return w.hit, w.halted
+}
+
+// Resume clears the halt so execution can continue.
+func (w *WatchBus) Resume() {
+	w.halted = false
+	w.hit = WatchHit{}
+}
+
+func (w *WatchBus) Read(addr uint16) byte {
+	value := w.bus.Read(addr)
+	if len(w.watchpoints) != 0 {
+		w.check(addr, WatchRead, value, value)
+	}
+	return value
+}
+
+func (w *WatchBus) Write(addr uint16, data byte) {
+	if len(w.watchpoints) == 0 {
+		w.bus.Write(addr, data)
+		return
+	}
+	old := w.bus.Peek(addr)
+	w.bus.Write(addr, data)
+	// Read back what was stored: ROM and register writes may not stick.
+	stored := w.bus.Peek(addr)
+	w.check(addr, WatchWrite, old, stored)
+	if stored != old {
+		w.check(addr, WatchChange, old, stored)
+	}
+}
+
+func (w *WatchBus) Peek(addr uint16) byte {
+	return w.bus.Peek(addr)
+}
+
+func (w *WatchBus) Poke(addr uint16, data byte) {
+	w.bus.Poke(addr, data)
+}
+
+// check fires every watchpoint of the given type covering addr.
+func (w *WatchBus) check(addr uint16, access AccessType, old, next byte) {
+	for _, wp := range w.watchpoints {
+		if wp.Type&access == 0 || addr < wp.Start || addr > wp.End {
+			continue
+		}
+		hit := WatchHit{Watchpoint: wp, Addr: addr, Access: access, Old: old, New: next}
+		if w.pc != nil {
+			hit.PC = w.pc()
+		}
+		if !w.halted {
+			w.hit = hit
+			w.halted = true
+		}
+		if w.OnHit != nil {
+			w.OnHit(hit)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package memory
+
+import "testing"
+
+func TestWatchBus_Write(t *testing.T) {
+	mmu := &MMU{}
+	pc := uint16(0x0150)
+	watch := NewWatchBus(mmu, func() uint16 { return pc })
+	watch.Add(0xC100, 0xC1FF, WatchWrite)
+
+	mmu.Write(0xC150, 0x10)
+	watch.Write(0xC000, 0x01) // outside the range
+	if watch.Halted() {
+		t.Fatalf("watchpoint fired for an address outside its range")
+	}
+
+	pc = 0x0234
+	watch.Write(0xC150, 0x20)
+	hit, ok := watch.LastHit()
+	if !ok {
+		t.Fatalf("write watchpoint did not fire")
+	}
+	if hit.PC != 0x0234 || hit.Addr != 0xC150 || hit.Access != WatchWrite || hit.Old != 0x10 || hit.New != 0x20 {
+		t.Errorf("hit = %+v; want PC 0x234 addr 0xC150 write 0x10 -> 0x20", hit)
+	}
+	if got := mmu.Read(0xC150); got != 0x20 {
+		t.Errorf("write did not complete, got 0x%X", got)
+	}
+
+	watch.Resume()
+	if watch.Halted() {
+		t.Errorf("Resume did not clear the halt")
+	}
+}
+
+func TestWatchBus_ChangeIgnoresSameValue(t *testing.T) {
+	mmu := &MMU{}
+	watch := NewWatchBus(mmu, nil)
+	watch.Add(0xFF80, 0xFF80, WatchChange)
+
+	mmu.Write(0xFF80, 0x42)
+	watch.Write(0xFF80, 0x42)
+	if watch.Halted() {
+		t.Fatalf("change watchpoint fired for an unchanged value")
+	}
+
+	watch.Write(0xFF80, 0x43)
+	hit, ok := watch.LastHit()
+	if !ok || hit.Access != WatchChange || hit.Old != 0x42 || hit.New != 0x43 {
+		t.Errorf("hit = %+v, %v; want change 0x42 -> 0x43", hit, ok)
+	}
+}
+
+func TestWatchBus_ReadAndRemove(t *testing.T) {
+	mmu := &MMU{}
+	mmu.Write(0xC000, 0x99)
+	watch := NewWatchBus(mmu, nil)
+	id := watch.Add(0xC000, 0xC000, WatchRead)
+
+	var hits []WatchHit
+	watch.OnHit = func(hit WatchHit) { hits = append(hits, hit) }
+
+	watch.Peek(0xC000)
+	if len(hits) != 0 {
+		t.Fatalf("Peek triggered a read watchpoint")
+	}
+
+	if got := watch.Read(0xC000); got != 0x99 {
+		t.Errorf("Read(0xC000) = 0x%X; want 0x99", got)
+	}
+	if len(hits) != 1 || hits[0].Access != WatchRead || hits[0].New != 0x99 {
+		t.Errorf("hits = %+v; want one read of 0x99", hits)
+	}
+
+	if !watch.Remove(id) {
+		t.Errorf("Remove(%d) = false", id)
+	}
+	watch.Resume()
+	watch.Read(0xC000)
+	if watch.Halted() || len(hits) != 1 {
+		t.Errorf("removed watchpoint still fired")
+	}
+}
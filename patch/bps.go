@@ -0,0 +1,94 @@
+package patch
+
+import "fmt"
+
+// BPS commands, the low 2 bits of each action.
+const (
+	bpsSourceRead = iota
+	bpsTargetRead
+	bpsSourceCopy
+	bpsTargetCopy
+)
+
+func applyBPS(rom, patch []byte) ([]byte, error) {
+	if len(patch) < len(bpsMagic)+12 {
+		return nil, fmt.Errorf("%w: BPS patch too short", ErrMalformed)
+	}
+	targetCRC, err := checkFooter(rom, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{data: patch, pos: len(bpsMagic), end: len(patch) - 12}
+	sourceSize, targetSize := r.varint(), r.varint()
+	r.bytes(r.varint()) // metadata, usually XML, ignored
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := checkSizes(sourceSize, targetSize); err != nil {
+		return nil, err
+	}
+	if sourceSize != len(rom) {
+		return nil, fmt.Errorf("%w: expected %d bytes, ROM has %d", ErrSourceSize, sourceSize, len(rom))
+	}
+
+	out := make([]byte, 0, targetSize)
+	var sourceRel, targetRel int
+	for r.pos < r.end && r.err == nil {
+		action := r.varint()
+		length := action>>2 + 1
+		if len(out)+length > targetSize {
+			r.fail("output overflows the target size")
+			break
+		}
+		switch action & 3 {
+		case bpsSourceRead:
+			at := len(out)
+			if at+length > len(rom) {
+				r.fail("source read past the end of the ROM")
+				break
+			}
+			out = append(out, rom[at:at+length]...)
+		case bpsTargetRead:
+			out = append(out, r.bytes(length)...)
+		case bpsSourceCopy:
+			sourceRel += signed(r.varint())
+			if sourceRel < 0 || sourceRel+length > len(rom) {
+				r.fail("source copy out of range")
+				break
+			}
+			out = append(out, rom[sourceRel:sourceRel+length]...)
+			sourceRel += length
+		case bpsTargetCopy:
+			targetRel += signed(r.varint())
+			if targetRel < 0 || targetRel >= len(out) {
+				r.fail("target copy out of range")
+				break
+			}
+			// byte by byte: the copy may overlap the bytes it produces
+			for i := 0; i < length; i++ {
+				out = append(out, out[targetRel])
+				targetRel++
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(out) != targetSize {
+		return nil, fmt.Errorf("%w: produced %d bytes, target is %d", ErrMalformed, len(out), targetSize)
+	}
+	if err := checkTarget(out, targetCRC); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// signed decodes a BPS relative offset: bit 0 is the sign, the rest the
+// magnitude.
+func signed(v int) int {
+	if v&1 != 0 {
+		return -(v >> 1)
+	}
+	return v >> 1
+}
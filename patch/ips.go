@@ -0,0 +1,51 @@
+package patch
+
+import "fmt"
+
+// ipsEOF is the record offset that ends the record list ("EOF").
+const ipsEOF = 0x454F46
+
+func applyIPS(rom, patch []byte) ([]byte, error) {
+	out := append([]byte(nil), rom...)
+	r := &reader{data: patch, pos: len(ipsMagic), end: len(patch)}
+	for {
+		offset := int(r.byte())<<16 | int(r.byte())<<8 | int(r.byte())
+		if r.err != nil {
+			return nil, r.err
+		}
+		if offset == ipsEOF {
+			break
+		}
+		size := int(r.byte())<<8 | int(r.byte())
+		var data []byte
+		if size == 0 {
+			size = int(r.byte())<<8 | int(r.byte())
+			value := r.byte()
+			data = make([]byte, size)
+			for i := range data {
+				data[i] = value
+			}
+		} else {
+			data = r.bytes(size)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		if offset+len(data) > maxSize {
+			return nil, fmt.Errorf("%w: record ends at offset %d", ErrTooLarge, offset+len(data))
+		}
+		if grow := offset + len(data) - len(out); grow > 0 {
+			out = append(out, make([]byte, grow)...)
+		}
+		copy(out[offset:], data)
+	}
+
+	// Some tools append the final file size after EOF to truncate.
+	if len(patch)-r.pos == 3 {
+		size := int(r.byte())<<16 | int(r.byte())<<8 | int(r.byte())
+		if size < len(out) {
+			out = out[:size]
+		}
+	}
+	return out, nil
+}
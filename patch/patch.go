@@ -0,0 +1,201 @@
+package patch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+)
+
+// Patch Formats
+// -----------------------------
+// IPS  "PATCH", records of offset (3 bytes BE) + size (2 bytes BE) + data,
+//      size 0 means an RLE record (run length + value), "EOF" ends the
+//      list, optionally followed by a 3-byte truncation length.
+// UPS  "UPS1", source and target sizes, then hunks of skip + XOR bytes.
+// BPS  "BPS1", source, target and metadata sizes, then copy commands.
+//
+// UPS and BPS end with the CRC32 of the source, the target and the patch
+// itself, so a patch applied to the wrong ROM is caught. IPS has no checks.
+// Sources: https://zerosoft.zophar.net/ips.php,
+// https://www.romhacking.net/documents/392/ (UPS),
+// https://www.romhacking.net/documents/746/ (BPS)
+// -----------------------------
+
+// Errors wrapped by Apply. The CRC errors carry the expected and actual
+// values in their message.
+var (
+	ErrUnknownFormat = errors.New("not an IPS, UPS or BPS patch")
+	ErrMalformed     = errors.New("malformed patch")
+	ErrPatchCRC      = errors.New("patch file is corrupt (patch CRC mismatch)")
+	ErrSourceCRC     = errors.New("patch is for a different ROM (source CRC mismatch)")
+	ErrSourceSize    = errors.New("patch is for a different ROM (source size mismatch)")
+	ErrTargetCRC     = errors.New("patched ROM is wrong (target CRC mismatch)")
+	ErrTooLarge      = errors.New("patch declares a ROM larger than any cartridge")
+)
+
+// maxSize is the largest ROM a cartridge header can declare, 8 MiB. Sizes
+// come straight from the patch, so they are checked before allocating.
+const maxSize = 8 << 20
+
+// Format names a patch format.
+type Format int
+
+const (
+	Unknown Format = iota
+	IPS
+	UPS
+	BPS
+)
+
+func (f Format) String() string {
+	switch f {
+	case IPS:
+		return "IPS"
+	case UPS:
+		return "UPS"
+	case BPS:
+		return "BPS"
+	}
+	return "unknown"
+}
+
+var (
+	ipsMagic = []byte("PATCH")
+	upsMagic = []byte("UPS1")
+	bpsMagic = []byte("BPS1")
+)
+
+// Detect returns the format of patch from its magic bytes.
+func Detect(patch []byte) Format {
+	switch {
+	case bytes.HasPrefix(patch, ipsMagic):
+		return IPS
+	case bytes.HasPrefix(patch, upsMagic):
+		return UPS
+	case bytes.HasPrefix(patch, bpsMagic):
+		return BPS
+	}
+	return Unknown
+}
+
+// Apply returns rom with patch applied. rom is never modified, the result is
+// always a new slice.
+func Apply(rom, patch []byte) ([]byte, error) {
+	switch Detect(patch) {
+	case IPS:
+		return applyIPS(rom, patch)
+	case UPS:
+		return applyUPS(rom, patch)
+	case BPS:
+		return applyBPS(rom, patch)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ApplyFiles reads each patch file and applies them to rom in order.
+// Errors name the patch that failed.
+func ApplyFiles(rom []byte, paths ...string) ([]byte, error) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if rom, err = Apply(rom, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return rom, nil
+}
+
+// checkFooter verifies the CRC32 footer shared by UPS and BPS: source,
+// target and patch CRCs, little-endian. It returns the target CRC for the
+// caller to check once the output is built.
+func checkFooter(rom, patch []byte) (target uint32, err error) {
+	footer := patch[len(patch)-12:]
+	wantSource := le32(footer[0:])
+	target = le32(footer[4:])
+	wantPatch := le32(footer[8:])
+
+	if got := crc32.ChecksumIEEE(patch[:len(patch)-4]); got != wantPatch {
+		return 0, fmt.Errorf("%w: expected %08X, got %08X", ErrPatchCRC, wantPatch, got)
+	}
+	if got := crc32.ChecksumIEEE(rom); got != wantSource {
+		return 0, fmt.Errorf("%w: expected %08X, ROM is %08X", ErrSourceCRC, wantSource, got)
+	}
+	return target, nil
+}
+
+func checkTarget(out []byte, want uint32) error {
+	if got := crc32.ChecksumIEEE(out); got != want {
+		return fmt.Errorf("%w: expected %08X, got %08X", ErrTargetCRC, want, got)
+	}
+	return nil
+}
+
+// checkSizes rejects UPS/BPS source and target sizes no cartridge has.
+func checkSizes(source, target int) error {
+	if source > maxSize || target > maxSize {
+		return fmt.Errorf("%w: source %d bytes, target %d bytes", ErrTooLarge, source, target)
+	}
+	return nil
+}
+
+func le32(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+// reader walks a patch, turning reads past the end into ErrMalformed.
+type reader struct {
+	data []byte
+	pos  int
+	end  int // reads stop here, before any footer
+	err  error
+}
+
+func (r *reader) byte() byte {
+	if r.pos >= r.end {
+		r.fail("unexpected end of patch")
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) bytes(n int) []byte {
+	if n < 0 || r.pos+n > r.end {
+		r.fail("unexpected end of patch")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+// varint decodes the UPS/BPS number encoding: 7 bits per byte, least
+// significant first, bit 7 marks the last byte, and each continuation
+// adds one so every value has a single encoding.
+func (r *reader) varint() int {
+	var value, shift uint64 = 0, 1
+	for r.err == nil {
+		x := r.byte()
+		value += uint64(x&0x7F) * shift
+		if x&0x80 != 0 {
+			break
+		}
+		shift <<= 7
+		value += shift
+		if shift > 1<<42 {
+			r.fail("number out of range")
+		}
+	}
+	return int(value)
+}
+
+func (r *reader) fail(msg string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %s at offset %d", ErrMalformed, msg, r.pos)
+	}
+}
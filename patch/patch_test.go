@@ -0,0 +1,216 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+func varint(v int) []byte {
+	var out []byte
+	for {
+		x := byte(v & 0x7F)
+		v >>= 7
+		if v == 0 {
+			return append(out, x|0x80)
+		}
+		out = append(out, x)
+		v--
+	}
+}
+
+// withFooter appends the source, target and patch CRC32s.
+func withFooter(p, source, target []byte) []byte {
+	p = binary.LittleEndian.AppendUint32(p, crc32.ChecksumIEEE(source))
+	p = binary.LittleEndian.AppendUint32(p, crc32.ChecksumIEEE(target))
+	return binary.LittleEndian.AppendUint32(p, crc32.ChecksumIEEE(p))
+}
+
+// makeUPS builds a UPS patch with one hunk per differing run.
+func makeUPS(source, target []byte) []byte {
+	p := append([]byte("UPS1"), varint(len(source))...)
+	p = append(p, varint(len(target))...)
+	at := func(b []byte, i int) byte {
+		if i < len(b) {
+			return b[i]
+		}
+		return 0
+	}
+	last := 0
+	for i := 0; i < len(target); i++ {
+		if at(source, i) == target[i] {
+			continue
+		}
+		p = append(p, varint(i-last)...)
+		for ; i < len(target) && at(source, i) != target[i]; i++ {
+			p = append(p, at(source, i)^target[i])
+		}
+		p = append(p, 0)
+		last = i + 1
+	}
+	return withFooter(p, source, target)
+}
+
+func testROM() []byte {
+	rom := make([]byte, 64)
+	for i := range rom {
+		rom[i] = byte(i)
+	}
+	return rom
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		data string
+		want Format
+	}{
+		{"PATCHxxx", IPS},
+		{"UPS1xxx", UPS},
+		{"BPS1xxx", BPS},
+		{"hello", Unknown},
+	}
+	for _, tt := range tests {
+		if got := Detect([]byte(tt.data)); got != tt.want {
+			t.Errorf("Detect(%q) = %s; want %s", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestApply_IPS(t *testing.T) {
+	rom := testROM()
+	p := []byte("PATCH")
+	p = append(p, 0x00, 0x00, 0x10, 0x00, 0x02, 0xAA, 0xBB)       // 2 bytes at 0x10
+	p = append(p, 0x00, 0x00, 0x3E, 0x00, 0x00, 0x00, 0x04, 0xCC) // RLE, grows the ROM
+	p = append(p, "EOF"...)
+
+	out, err := Apply(rom, p)
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	want := append(testROM()[:0x3E], 0xCC, 0xCC, 0xCC, 0xCC)
+	want[0x10], want[0x11] = 0xAA, 0xBB
+	if !bytes.Equal(out, want) {
+		t.Errorf("Apply() = % X\nwant      % X", out, want)
+	}
+	if !bytes.Equal(rom, testROM()) {
+		t.Error("Apply() modified the source ROM")
+	}
+
+	truncated, err := Apply(rom, append(append([]byte("PATCH"), "EOF"...), 0x00, 0x00, 0x20))
+	if err != nil || len(truncated) != 0x20 {
+		t.Errorf("truncating patch: len %d, error %v; want 32 bytes", len(truncated), err)
+	}
+}
+
+func TestApply_UPS(t *testing.T) {
+	rom := testROM()
+	target := append(testROM(), 0x77, 0x88)
+	target[3], target[4], target[40] = 0xFF, 0xFE, 0x00
+	p := makeUPS(rom, target)
+
+	out, err := Apply(rom, p)
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if !bytes.Equal(out, target) {
+		t.Errorf("Apply() = % X\nwant      % X", out, target)
+	}
+}
+
+func TestApply_BPS(t *testing.T) {
+	rom := testROM()
+	p := append([]byte("BPS1"), varint(len(rom))...)
+	p = append(p, varint(72)...)
+	p = append(p, varint(3)...)
+	p = append(p, "abc"...)                           // metadata
+	p = append(p, varint((16-1)<<2|bpsSourceRead)...) // 0x00-0x0F
+	p = append(p, varint((8-1)<<2|bpsSourceCopy)...)  // 8 bytes from 0x30
+	p = append(p, varint(0x30<<1)...)
+	p = append(p, varint((2-1)<<2|bpsTargetRead)...) // 2 literal bytes
+	p = append(p, 0xAA, 0xBB)
+	p = append(p, varint((6-1)<<2|bpsTargetCopy)...) // overlapping repeat of AA BB
+	p = append(p, varint(24<<1)...)
+	p = append(p, varint((40-1)<<2|bpsSourceCopy)...) // back to 0x18 for the rest
+	p = append(p, varint((0x38-0x18)<<1|1)...)
+
+	var target []byte
+	target = append(target, rom[:16]...)
+	target = append(target, rom[0x30:0x38]...)
+	target = append(target, 0xAA, 0xBB, 0xAA, 0xBB, 0xAA, 0xBB, 0xAA, 0xBB)
+	target = append(target, rom[0x18:0x40]...)
+	p = withFooter(p, rom, target)
+
+	out, err := Apply(rom, p)
+	if err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if !bytes.Equal(out, target) {
+		t.Errorf("Apply() = % X\nwant      % X", out, target)
+	}
+}
+
+func TestApply_CRCErrors(t *testing.T) {
+	rom := testROM()
+	target := testROM()
+	target[0] = 0x99
+	good := makeUPS(rom, target)
+
+	wrongROM := testROM()
+	wrongROM[1] = 0x55
+	if _, err := Apply(wrongROM, good); !errors.Is(err, ErrSourceCRC) {
+		t.Errorf("wrong ROM: error = %v; want ErrSourceCRC", err)
+	}
+
+	corrupt := append([]byte(nil), good...)
+	corrupt[8] ^= 0x01
+	if _, err := Apply(rom, corrupt); !errors.Is(err, ErrPatchCRC) {
+		t.Errorf("corrupt patch: error = %v; want ErrPatchCRC", err)
+	}
+
+	// Valid patch checksum, but the target CRC doesn't match the output.
+	badTarget := makeUPS(rom, target)
+	binary.LittleEndian.PutUint32(badTarget[len(badTarget)-8:], 0x12345678)
+	badTarget = withFooter(badTarget[:len(badTarget)-12], rom, []byte("not it"))
+	if _, err := Apply(rom, badTarget); !errors.Is(err, ErrTargetCRC) {
+		t.Errorf("bad target: error = %v; want ErrTargetCRC", err)
+	}
+}
+
+func TestApply_Malformed(t *testing.T) {
+	if _, err := Apply(testROM(), []byte("PATCH\x00\x00")); !errors.Is(err, ErrMalformed) {
+		t.Errorf("truncated IPS: error = %v; want ErrMalformed", err)
+	}
+	if _, err := Apply(testROM(), []byte("nope")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("error = %v; want ErrUnknownFormat", err)
+	}
+}
+
+func TestApply_TooLarge(t *testing.T) {
+	rom := testROM()
+	huge := 1 << 40
+
+	ups := append([]byte("UPS1"), varint(len(rom))...)
+	ups = withFooter(append(ups, varint(huge)...), rom, nil)
+
+	bps := append([]byte("BPS1"), varint(len(rom))...)
+	bps = append(bps, varint(huge)...)
+	bps = withFooter(append(bps, varint(0)...), rom, nil)
+
+	ips := []byte("PATCH\x80\x00\x00\x00\x01\xFFEOF") // one byte at 8 MiB
+
+	tests := []struct {
+		name  string
+		patch []byte
+	}{
+		{"UPS", ups},
+		{"BPS", bps},
+		{"IPS", ips},
+	}
+	for _, tt := range tests {
+		if _, err := Apply(rom, tt.patch); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: error = %v; want ErrTooLarge", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package patch
+
+import "fmt"
+
+func applyUPS(rom, patch []byte) ([]byte, error) {
+	if len(patch) < len(upsMagic)+12 {
+		return nil, fmt.Errorf("%w: UPS patch too short", ErrMalformed)
+	}
+	targetCRC, err := checkFooter(rom, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{data: patch, pos: len(upsMagic), end: len(patch) - 12}
+	sourceSize, targetSize := r.varint(), r.varint()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := checkSizes(sourceSize, targetSize); err != nil {
+		return nil, err
+	}
+	if sourceSize != len(rom) {
+		return nil, fmt.Errorf("%w: expected %d bytes, ROM has %d", ErrSourceSize, sourceSize, len(rom))
+	}
+
+	// The target starts as the source; hunks XOR bytes in place. Bytes past
+	// the end of the source XOR against zero.
+	out := make([]byte, targetSize)
+	copy(out, rom)
+	pos := 0
+	for r.pos < r.end && r.err == nil {
+		pos += r.varint()
+		for r.err == nil {
+			x := r.byte()
+			if pos < len(out) {
+				out[pos] ^= x
+			}
+			pos++
+			if x == 0 {
+				break
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := checkTarget(out, targetCRC); err != nil {
+		return nil, err
+	}
+	return out, nil
+}